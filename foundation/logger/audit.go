@@ -0,0 +1,241 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// constant 'auditKey' is the field holding the audit entry of an audit record
+const auditKey = "audit"
+
+// constants for the outcome of an audited action
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDenied  = "denied"
+)
+
+// struct 'AuditEvent' represents a security relevant action in the fixed audit schema,
+// 'Details' are additional fields covered by the hash chain as well
+type AuditEvent struct {
+	Actor    string
+	Action   string
+	Resource string
+	Outcome  string
+	Details  []Field
+}
+
+// struct 'Auditor' represents an audit trail written through a logger,
+// records are logged at the 'Audit' level which bypasses level filtering and is never dropped,
+// every record carries a hash chain linking it to the previous one so deleted or altered lines
+// are detected by 'VerifyAuditLog', the trail is kept separate from application logs by giving
+// the auditor a logger with handlers of its own, e.g. 'Logger.WithHandlers' with a 'FileHandler'
+// using the JSON format
+type Auditor struct {
+	logger *Logger
+	mu     sync.Mutex
+	seq    uint64
+	prev   string
+}
+
+// struct 'AuditVerifyError' represents a break of the hash chain found by 'VerifyAuditLog'
+type AuditVerifyError struct {
+	Line   int
+	Seq    uint64
+	Reason string
+}
+
+// function 'Error' returns the location and reason of the break
+func (e *AuditVerifyError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("audit log: %s", e.Reason)
+	}
+	return fmt.Sprintf("audit log: line %d (seq %d): %s", e.Line, e.Seq, e.Reason)
+}
+
+// struct 'auditLine' represents an audit entry read back from a log file
+type auditLine struct {
+	line  int
+	seq   uint64
+	entry map[string]any
+}
+
+// function 'NewAuditor' creates a new auditor writing to the given logger,
+// the chain starts at sequence number 1, see 'Resume' to continue an existing trail
+func NewAuditor(l *Logger) *Auditor {
+	return &Auditor{logger: l}
+}
+
+// function 'Resume' verifies an existing trail and continues its chain,
+// it is used when a restarted process appends to the file written before
+func (a *Auditor) Resume(r io.Reader) error {
+	last, err := verifyAuditLog(r)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if last != nil {
+		a.seq = last.seq
+		a.prev, _ = last.entry["hash"].(string)
+	}
+	return nil
+}
+
+// function 'Log' writes an audit record for the given event,
+// the fields carried by the context, e.g. the request id, are added next to the audit entry
+func (a *Auditor) Log(ctx context.Context, event AuditEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entry := map[string]any{
+		"seq":       a.seq + 1,
+		"time":      a.logger.config.clock.Now().UTC().Format(time.RFC3339Nano),
+		"actor":     event.Actor,
+		"action":    event.Action,
+		"resource":  event.Resource,
+		"outcome":   event.Outcome,
+		"prev_hash": a.prev,
+	}
+	if len(event.Details) > 0 {
+		entry["details"] = jsonValue(event.Details)
+	}
+
+	hash, err := auditHash(entry)
+	if err != nil {
+		a.logger.dispatcher.reportInternalError(fmt.Errorf("audit record of %q not written: %w", event.Action, err))
+		return
+	}
+	entry["hash"] = hash
+	a.seq++
+	a.prev = hash
+
+	// the lock is held while dispatching so records enter the queue in chain order
+	a.logger.log(ctx, Audit, "audit: "+event.Action, []Field{Map(auditKey, entry)})
+}
+
+// function 'VerifyAuditLog' verifies the hash chain of the audit records read from r,
+// the lines are expected to be JSON documents with the audit entry under the "audit" key as written by
+// 'JSONFormatter', other lines are ignored, it detects deleted, altered, inserted and reordered records,
+// records written concurrently by several dispatcher workers may be out of order and are sorted first,
+// it returns the number of verified records, records cut from the end of the log are only detected by
+// comparing this number with the sequence number last written
+func VerifyAuditLog(r io.Reader) (int, error) {
+	lines, err := readAuditLines(r)
+	if err != nil {
+		return 0, err
+	}
+	if err := verifyAuditLines(lines); err != nil {
+		return 0, err
+	}
+	return len(lines), nil
+}
+
+// function 'verifyAuditLog' verifies the records read from r and returns the last one, nil when there are none
+func verifyAuditLog(r io.Reader) (*auditLine, error) {
+	lines, err := readAuditLines(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyAuditLines(lines); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	return &lines[len(lines)-1], nil
+}
+
+// function 'readAuditLines' reads the audit entries of a log sorted by sequence number
+func readAuditLines(r io.Reader) ([]auditLine, error) {
+	var lines []auditLine
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFrameSize)
+	for n := 1; scanner.Scan(); n++ {
+		var doc map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+			continue
+		}
+		entry, ok := doc[auditKey].(map[string]any)
+		if !ok {
+			continue
+		}
+		seq, ok := entry["seq"].(float64)
+		if !ok || seq < 1 || seq != float64(uint64(seq)) {
+			return nil, &AuditVerifyError{Line: n, Reason: "missing or invalid sequence number"}
+		}
+		lines = append(lines, auditLine{line: n, seq: uint64(seq), entry: entry})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("audit log: %w", err)
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].seq < lines[j].seq
+	})
+	return lines, nil
+}
+
+// function 'verifyAuditLines' checks the sequence numbers and hashes of sorted audit entries
+func verifyAuditLines(lines []auditLine) error {
+	prev := ""
+	for i, l := range lines {
+		expected := uint64(i) + 1
+		switch {
+		case l.seq < expected:
+			return &AuditVerifyError{Line: l.line, Seq: l.seq, Reason: "duplicate sequence number"}
+		case l.seq == expected+1:
+			return &AuditVerifyError{Line: l.line, Seq: l.seq, Reason: fmt.Sprintf("record %d is missing", expected)}
+		case l.seq > expected:
+			return &AuditVerifyError{Line: l.line, Seq: l.seq, Reason: fmt.Sprintf("records %d to %d are missing", expected, l.seq-1)}
+		}
+
+		if link, _ := l.entry["prev_hash"].(string); link != prev {
+			return &AuditVerifyError{Line: l.line, Seq: l.seq, Reason: "previous hash does not match the previous record"}
+		}
+		hash, _ := l.entry["hash"].(string)
+		content := make(map[string]any, len(l.entry))
+		for k, v := range l.entry {
+			if k != "hash" {
+				content[k] = v
+			}
+		}
+		computed, err := auditHash(content)
+		if err != nil {
+			return &AuditVerifyError{Line: l.line, Seq: l.seq, Reason: err.Error()}
+		}
+		if computed != hash {
+			return &AuditVerifyError{Line: l.line, Seq: l.seq, Reason: "record was altered, hash does not match its content"}
+		}
+		prev = hash
+	}
+	return nil
+}
+
+// function 'auditHash' returns the hex encoded SHA-256 of the canonical JSON encoding of an entry,
+// the entry is encoded, decoded and encoded again so the written and the read back entries
+// hash the same, e.g. integers written by the auditor are read back as floats
+func auditHash(entry map[string]any) (string, error) {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	var canonical any
+	if err := json.Unmarshal(b, &canonical); err != nil {
+		return "", err
+	}
+	if b, err = json.Marshal(canonical); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
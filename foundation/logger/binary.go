@@ -0,0 +1,324 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// constant 'maxFrameSize' is the largest frame accepted by 'RecordReader'
+const maxFrameSize = 16 << 20
+
+// type 'BinaryFormatter' represents a formatter producing length-delimited binary frames,
+// concrete implementations are 'CBORFormatter' and 'MsgPackFormatter',
+// every frame is the payload size as an unsigned varint followed by the encoded record
+type BinaryFormatter interface {
+	Formatter
+	// function 'Decode' decodes the payload of a single frame back into a record
+	Decode(payload []byte) (Record, error)
+}
+
+// constants for the keys of an encoded record
+const (
+	binaryKeyTimestamp = "ts"
+	binaryKeyLevel     = "level"
+	binaryKeyMessage   = "msg"
+	binaryKeyTraceId   = "trace_id"
+	binaryKeyCaller    = "caller"
+	binaryKeyFields    = "fields"
+	binaryKeyStack     = "stack"
+)
+
+// struct 'RecordReader' reads records from a stream of frames written by a 'BinaryFormatter'
+type RecordReader struct {
+	r         *bufio.Reader
+	formatter BinaryFormatter
+}
+
+// function 'NewRecordReader' creates a new 'RecordReader' decoding frames with the given formatter
+func NewRecordReader(r io.Reader, formatter BinaryFormatter) *RecordReader {
+	return &RecordReader{r: bufio.NewReader(r), formatter: formatter}
+}
+
+// function 'Read' reads the next record, it returns 'io.EOF' at the end of the stream
+func (rr *RecordReader) Read() (Record, error) {
+	size, err := binary.ReadUvarint(rr.r)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return Record{}, io.EOF
+		}
+		return Record{}, fmt.Errorf("read frame size: %w", err)
+	}
+	if size > maxFrameSize {
+		return Record{}, fmt.Errorf("frame of %d bytes exceeds limit of %d bytes", size, maxFrameSize)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(rr.r, payload); err != nil {
+		return Record{}, fmt.Errorf("read frame of %d bytes: %w", size, io.ErrUnexpectedEOF)
+	}
+	return rr.formatter.Decode(payload)
+}
+
+// function 'ConvertToJSON' reads binary frames from src and writes them to dst as indented JSON for humans
+func ConvertToJSON(dst io.Writer, src io.Reader, formatter BinaryFormatter) error {
+	rr := NewRecordReader(src, formatter)
+	profile := DefaultProfile()
+	for {
+		r, err := rr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		doc := profile(r)
+		if r.TraceId != "" {
+			doc["trace_id"] = r.TraceId
+		}
+		b, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encode record as json: %w", err)
+		}
+		if _, err := dst.Write(append(b, '\n')); err != nil {
+			return err
+		}
+	}
+}
+
+// function 'frame' prefixes the given payload with its size
+func frame(payload []byte) []byte {
+	out := make([]byte, 0, len(payload)+binary.MaxVarintLen64)
+	out = binary.AppendUvarint(out, uint64(len(payload)))
+	return append(out, payload...)
+}
+
+// function 'binaryRecord' returns the record as the ordered map written by the binary formatters
+func binaryRecord(r Record) []Field {
+	doc := make([]Field, 0, 6)
+	doc = append(doc,
+		Field{binaryKeyTimestamp, r.Timestamp},
+		Field{binaryKeyLevel, r.Level.String()},
+		Field{binaryKeyMessage, r.Message},
+	)
+	if r.TraceId != "" {
+		doc = append(doc, Field{binaryKeyTraceId, r.TraceId})
+	}
+	if r.Caller != "" {
+		doc = append(doc, Field{binaryKeyCaller, r.Caller})
+	}
+	if len(r.Fields) > 0 {
+		doc = append(doc, Field{binaryKeyFields, r.Fields})
+	}
+	if len(r.Stack) > 0 {
+		doc = append(doc, Field{binaryKeyStack, StackString(r.Stack)})
+	}
+	return doc
+}
+
+// function 'recordFromBinary' builds a record from the ordered map read by the binary formatters
+func recordFromBinary(doc []Field) (Record, error) {
+	var r Record
+	for _, entry := range doc {
+		var ok bool
+		switch entry.Key {
+		case binaryKeyTimestamp:
+			r.Timestamp, ok = entry.Value.(time.Time)
+		case binaryKeyLevel:
+			var name string
+			if name, ok = entry.Value.(string); ok {
+				level, err := parseRecordLevel(name)
+				if err != nil {
+					return Record{}, err
+				}
+				r.Level = level
+			}
+		case binaryKeyMessage:
+			r.Message, ok = entry.Value.(string)
+		case binaryKeyTraceId:
+			r.TraceId, ok = entry.Value.(string)
+		case binaryKeyCaller:
+			r.Caller, ok = entry.Value.(string)
+		case binaryKeyFields:
+			r.Fields, ok = entry.Value.([]Field)
+		case binaryKeyStack:
+			var stack string
+			if stack, ok = entry.Value.(string); ok {
+				r.Stack = parseStack(stack)
+			}
+		default:
+			ok = true
+		}
+		if !ok {
+			return Record{}, fmt.Errorf("unexpected %T for record key %q", entry.Value, entry.Key)
+		}
+	}
+	return r, nil
+}
+
+// function 'normalize' converts a field value into one of the types the binary encoders write:
+// nil, bool, int64, uint64, float64, string, []byte, time.Time, []any or []Field for maps and groups
+func normalize(v any) any {
+	switch val := v.(type) {
+	case nil, bool, int64, uint64, float64, string, []byte, time.Time, []Field:
+		return val
+	case int:
+		return int64(val)
+	case int8:
+		return int64(val)
+	case int16:
+		return int64(val)
+	case int32:
+		return int64(val)
+	case uint:
+		return uint64(val)
+	case uint8:
+		return uint64(val)
+	case uint16:
+		return uint64(val)
+	case uint32:
+		return uint64(val)
+	case float32:
+		return float64(val)
+	case time.Duration:
+		return val.String()
+	case ErrorValue:
+		return normalize(val.Object())
+	case error:
+		return val.Error()
+	case fmt.Stringer:
+		return val.String()
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fields := make([]Field, 0, len(keys))
+		for _, k := range keys {
+			fields = append(fields, Field{k, val[k]})
+		}
+		return fields
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]any, rv.Len())
+		for i := range items {
+			items[i] = rv.Index(i).Interface()
+		}
+		return items
+	case reflect.Map:
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		fields := make([]Field, 0, len(keys))
+		for _, k := range keys {
+			fields = append(fields, Field{fmt.Sprint(k.Interface()), rv.MapIndex(k).Interface()})
+		}
+		return fields
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		return normalize(rv.Elem().Interface())
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// constant 'maxDecodeDepth' limits the nesting of decoded values,
+// deeper payloads are rejected so a hostile frame cannot exhaust the stack of the decoder
+const maxDecodeDepth = 64
+
+// struct 'byteReader' reads big-endian values from a payload with bounds checking,
+// 'depth' is the nesting level of the value being decoded
+type byteReader struct {
+	b     []byte
+	off   int
+	depth int
+}
+
+// variables for errors of malformed payloads,
+// 'errShortPayload' is returned when a payload ends in the middle of a value,
+// 'errTooDeep' is returned when values are nested deeper than 'maxDecodeDepth'
+var (
+	errShortPayload = errors.New("payload ends in the middle of a value")
+	errTooDeep      = fmt.Errorf("values nested deeper than %d levels", maxDecodeDepth)
+)
+
+// function 'enter' descends into a nested value, it fails when the nesting limit is exceeded
+func (br *byteReader) enter() error {
+	if br.depth >= maxDecodeDepth {
+		return errTooDeep
+	}
+	br.depth++
+	return nil
+}
+
+// function 'leave' returns from a nested value
+func (br *byteReader) leave() {
+	br.depth--
+}
+
+// function 'next' returns the next n bytes
+func (br *byteReader) next(n uint64) ([]byte, error) {
+	if n > uint64(len(br.b)-br.off) {
+		return nil, errShortPayload
+	}
+	out := br.b[br.off : br.off+int(n)]
+	br.off += int(n)
+	return out, nil
+}
+
+// function 'byte' returns the next byte
+func (br *byteReader) byte() (byte, error) {
+	b, err := br.next(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// function 'uint' returns the next big-endian unsigned integer of the given byte size
+func (br *byteReader) uint(size int) (uint64, error) {
+	b, err := br.next(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+// function 'done' reports an error when the payload has trailing bytes
+func (br *byteReader) done() error {
+	if br.off != len(br.b) {
+		return fmt.Errorf("%d trailing bytes after record", len(br.b)-br.off)
+	}
+	return nil
+}
+
+// function 'decodedInt' returns a decoded unsigned integer as int64 when it fits
+func decodedInt(n uint64) any {
+	if n <= math.MaxInt64 {
+		return int64(n)
+	}
+	return n
+}
@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// function 'binaryTestRecord' returns a record exercising every value type of the binary formats
+func binaryTestRecord() Record {
+	return Record{
+		Level:     Warn,
+		Message:   "payment declined",
+		TraceId:   "4bf92f3577b34da6a3ce929d0e0e4736",
+		Caller:    "payments/charge.go:88",
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 678900000, time.UTC),
+		Fields: []Field{
+			String("customer", "c-123"),
+			Int("amount", 4200),
+			Bool("retry", false),
+			{Key: "ratio", Value: 0.25},
+			{Key: "negative", Value: -17},
+			{Key: "missing", Value: nil},
+			Group("card", String("brand", "visa"), Int("last4", 4242)),
+			{Key: "tags", Value: []any{"a", int64(1)}},
+		},
+		Stack: []StackFrame{{Function: "main.charge", File: "payments/charge.go", Line: 88}},
+	}
+}
+
+func TestBinaryFormattersRoundTrip(t *testing.T) {
+	for name, f := range map[string]BinaryFormatter{"cbor": NewCBORFormatter(), "msgpack": NewMsgPackFormatter()} {
+		t.Run(name, func(t *testing.T) {
+			want := binaryTestRecord()
+			var stream bytes.Buffer
+			stream.Write(f.Format(want))
+			stream.Write(f.Format(Record{Level: Info, Message: "second"}))
+
+			rr := NewRecordReader(&stream, f)
+			got, err := rr.Read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Level != want.Level || got.Message != want.Message || got.TraceId != want.TraceId || got.Caller != want.Caller {
+				t.Errorf("got header %+v", got)
+			}
+			if !got.Timestamp.Equal(want.Timestamp) {
+				t.Errorf("got timestamp %v, want %v", got.Timestamp, want.Timestamp)
+			}
+			if len(got.Stack) != 1 || got.Stack[0] != want.Stack[0] {
+				t.Errorf("got stack %v", got.Stack)
+			}
+
+			fields := map[string]any{}
+			for _, field := range got.Fields {
+				fields[field.Key] = field.Value
+			}
+			for key, value := range map[string]any{
+				"customer": "c-123", "amount": int64(4200), "retry": false, "ratio": 0.25, "negative": int64(-17), "missing": nil,
+			} {
+				if fields[key] != value {
+					t.Errorf("got %s=%#v, want %#v", key, fields[key], value)
+				}
+			}
+			if card, ok := fields["card"].([]Field); !ok || len(card) != 2 || card[0] != (Field{"brand", "visa"}) {
+				t.Errorf("got card %#v", fields["card"])
+			}
+
+			if second, err := rr.Read(); err != nil || second.Message != "second" {
+				t.Errorf("got %+v, %v", second, err)
+			}
+			if _, err := rr.Read(); !errors.Is(err, io.EOF) {
+				t.Errorf("got %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestBinaryFormattersRejectDeepNesting(t *testing.T) {
+	const depth = 1 << 20
+	// an array holding an array holding an array ..., one byte per level
+	cbor := bytes.Repeat([]byte{0x81}, depth)
+	msgpack := bytes.Repeat([]byte{0x91}, depth)
+
+	if _, err := NewCBORFormatter().Decode(cbor); !errors.Is(err, errTooDeep) {
+		t.Errorf("cbor: got %v, want %v", err, errTooDeep)
+	}
+	if _, err := NewMsgPackFormatter().Decode(msgpack); !errors.Is(err, errTooDeep) {
+		t.Errorf("msgpack: got %v, want %v", err, errTooDeep)
+	}
+}
+
+func TestBinaryFormattersRejectTruncatedFrames(t *testing.T) {
+	for name, f := range map[string]BinaryFormatter{"cbor": NewCBORFormatter(), "msgpack": NewMsgPackFormatter()} {
+		frame := f.Format(binaryTestRecord())
+		if _, err := NewRecordReader(bytes.NewReader(frame[:len(frame)-3]), f).Read(); err == nil {
+			t.Errorf("%s: expected an error for a truncated frame", name)
+		}
+	}
+}
+
+func BenchmarkFormatJSON(b *testing.B) {
+	benchmarkFormat(b, NewJSONFormatter())
+}
+
+func BenchmarkFormatCBOR(b *testing.B) {
+	benchmarkFormat(b, NewCBORFormatter())
+}
+
+func BenchmarkFormatMsgPack(b *testing.B) {
+	benchmarkFormat(b, NewMsgPackFormatter())
+}
+
+func benchmarkFormat(b *testing.B, f Formatter) {
+	r := binaryTestRecord()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(f.Format(r))))
+	for i := 0; i < b.N; i++ {
+		f.Format(r)
+	}
+}
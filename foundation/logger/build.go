@@ -1,5 +1,7 @@
 package logger
 
+import "os"
+
 // type 'BuildInfo' represents build information of the application
 type BuildInfo struct {
 	BuildVersion string
@@ -9,21 +11,27 @@ type BuildInfo struct {
 
 // function 'NewBuildInfo' creates a new build info instance,
 // it takes version key, commit key, and time environment variable key as arguments,
-// if the environment variable is not set, it returns the key name itself,
+// if an environment variable is not set, the matching value is left empty,
 // it returns a new instance of 'BuildInfo' with the current build information
 func NewBuildInfo(versionKey, commitKey, timeKey string) BuildInfo {
 	return BuildInfo{
-		BuildVersion: getEnvOrKey(versionKey),
-		BuildCommit:  getEnvOrKey(commitKey),
-		BuildTime:    getEnvOrKey(timeKey),
+		BuildVersion: os.Getenv(versionKey),
+		BuildCommit:  os.Getenv(commitKey),
+		BuildTime:    os.Getenv(timeKey),
 	}
 }
 
-// function 'Fields' returns the build info as a slice of 'Field' for logging
+// function 'Fields' returns the build info as a slice of 'Field' for logging, empty values are omitted
 func (s BuildInfo) Fields() []Field {
-	return []Field{
+	fields := make([]Field, 0, 3)
+	for _, field := range []Field{
 		{"build_version", s.BuildVersion},
 		{"build_commit", s.BuildCommit},
 		{"build_time", s.BuildTime},
+	} {
+		if field.Value != "" {
+			fields = append(fields, field)
+		}
 	}
+	return fields
 }
@@ -1,24 +1,132 @@
 package logger
 
 import (
-	"fmt"
 	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
 )
 
-// function 'caller' returns the caller's file and line number
-func caller(skip int) string {
-	_, file, line, ok := runtime.Caller(skip)
-	if !ok {
+// type 'CallerFormat' represents how the caller of a log call is reported
+type CallerFormat int
+
+// constants for caller formats,
+// 'CallerBasename' reports "file.go:12",
+// 'CallerRelative' reports the path relative to the main module, e.g. "foundation/logger/file.go:12",
+// 'CallerFullPath' reports the absolute path of the file,
+// 'CallerFunction' reports the function as "package.Function",
+// 'CallerNone' disables caller capture entirely
+const (
+	CallerBasename CallerFormat = iota
+	CallerRelative
+	CallerFullPath
+	CallerFunction
+	CallerNone
+)
+
+// struct 'callerKey' is the key of the caller cache
+type callerKey struct {
+	pc     uintptr
+	format CallerFormat
+}
+
+// variable 'callerCache' caches formatted callers by program counter,
+// a program counter always resolves to the same frame so entries never go stale
+var callerCache sync.Map
+
+// variable 'mainModule' is the path of the main module used by 'CallerRelative'
+var mainModule = sync.OnceValue(func() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Path
+	}
+	return ""
+})
+
+// function 'caller' returns the caller in the given format,
+// skip counts the frames above the function calling 'caller', as in 'runtime.Caller'
+func caller(skip int, format CallerFormat) string {
+	if format == CallerNone {
+		return ""
+	}
+
+	var pcs [1]uintptr
+	if runtime.Callers(skip+1, pcs[:]) == 0 {
+		return "unknown:0"
+	}
+
+	key := callerKey{pc: pcs[0], format: format}
+	if v, ok := callerCache.Load(key); ok {
+		return v.(string)
+	}
+
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	formatted := formatFrame(frame, format)
+	callerCache.Store(key, formatted)
+	return formatted
+}
+
+// function 'formatFrame' formats a resolved stack frame
+func formatFrame(frame runtime.Frame, format CallerFormat) string {
+	if frame.File == "" {
 		return "unknown:0"
 	}
+	line := ":" + strconv.Itoa(frame.Line)
+
+	switch format {
+	case CallerFullPath:
+		return frame.File + line
+	case CallerFunction:
+		return shortFunction(frame.Function)
+	case CallerRelative:
+		return relativePath(frame) + line
+	default:
+		return basename(frame.File) + line
+	}
+}
+
+// function 'basename' returns the last element of a slash separated path
+func basename(path string) string {
+	if i := strings.LastIndexByte(path, '/'); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
 
-	short := file
-	for i := len(file) - 1; i >= 0; i-- {
-		if file[i] == '/' {
-			short = file[i+1:]
-			break
+// function 'shortFunction' trims the import path of a function name,
+// e.g. "github.com/a/b/pkg.(*T).Method" becomes "pkg.(*T).Method"
+func shortFunction(function string) string {
+	return basename(function)
+}
+
+// function 'relativePath' returns the file path of the frame relative to the main module,
+// the package import path is derived from the function name so it works with and without -trimpath,
+// files of other modules are reported with their full package import path
+func relativePath(frame runtime.Frame) string {
+	pkg := packagePath(frame.Function)
+	if pkg == "" || pkg == "main" {
+		return basename(frame.File)
+	}
+	if module := mainModule(); module != "" {
+		if pkg == module {
+			return basename(frame.File)
+		}
+		if rel, ok := strings.CutPrefix(pkg, module+"/"); ok {
+			pkg = rel
 		}
 	}
+	return pkg + "/" + basename(frame.File)
+}
 
-	return fmt.Sprintf("%s:%d", short, line)
+// function 'packagePath' returns the import path of the package declaring the given function
+func packagePath(function string) string {
+	dir := ""
+	name := function
+	if i := strings.LastIndexByte(function, '/'); i >= 0 {
+		dir, name = function[:i+1], function[i+1:]
+	}
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		name = name[:i]
+	}
+	return dir + name
 }
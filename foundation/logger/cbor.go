@@ -0,0 +1,261 @@
+package logger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// constants for CBOR major types as defined in RFC 8949
+const (
+	cborUint   byte = 0 << 5
+	cborNegint byte = 1 << 5
+	cborBytes  byte = 2 << 5
+	cborText   byte = 3 << 5
+	cborArray  byte = 4 << 5
+	cborMap    byte = 5 << 5
+	cborTag    byte = 6 << 5
+	cborSimple byte = 7 << 5
+)
+
+// constant 'cborTagDateTime' is the tag of an RFC 3339 date/time string
+const cborTagDateTime = 0
+
+// struct 'CBORFormatter' implements 'BinaryFormatter' interface,
+// it encodes records as CBOR maps, timestamps are written as tagged RFC 3339 strings
+type CBORFormatter struct{}
+
+// function 'NewCBORFormatter' creates a new 'CBORFormatter'
+func NewCBORFormatter() *CBORFormatter {
+	return &CBORFormatter{}
+}
+
+// function 'Format' formats the given record as a length-delimited CBOR frame
+func (f *CBORFormatter) Format(r Record) []byte {
+	return frame(cborAppend(nil, binaryRecord(r)))
+}
+
+// function 'Decode' decodes a CBOR payload back into a record
+func (f *CBORFormatter) Decode(payload []byte) (Record, error) {
+	br := &byteReader{b: payload}
+	v, err := cborDecode(br)
+	if err != nil {
+		return Record{}, fmt.Errorf("cbor: %w", err)
+	}
+	if err := br.done(); err != nil {
+		return Record{}, fmt.Errorf("cbor: %w", err)
+	}
+	doc, ok := v.([]Field)
+	if !ok {
+		return Record{}, fmt.Errorf("cbor: record is %T, not a map", v)
+	}
+	return recordFromBinary(doc)
+}
+
+// function 'cborHead' appends the initial byte and argument of a data item
+func cborHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, major|27), n)
+	}
+}
+
+// function 'cborAppend' appends the CBOR encoding of a value
+func cborAppend(b []byte, v any) []byte {
+	switch val := normalize(v).(type) {
+	case nil:
+		return append(b, cborSimple|22)
+	case bool:
+		if val {
+			return append(b, cborSimple|21)
+		}
+		return append(b, cborSimple|20)
+	case int64:
+		if val < 0 {
+			return cborHead(b, cborNegint, uint64(-(val + 1)))
+		}
+		return cborHead(b, cborUint, uint64(val))
+	case uint64:
+		return cborHead(b, cborUint, val)
+	case float64:
+		return binary.BigEndian.AppendUint64(append(b, cborSimple|27), math.Float64bits(val))
+	case string:
+		return append(cborHead(b, cborText, uint64(len(val))), val...)
+	case []byte:
+		return append(cborHead(b, cborBytes, uint64(len(val))), val...)
+	case time.Time:
+		b = cborHead(b, cborTag, cborTagDateTime)
+		return cborAppend(b, val.Format(time.RFC3339Nano))
+	case []any:
+		b = cborHead(b, cborArray, uint64(len(val)))
+		for _, item := range val {
+			b = cborAppend(b, item)
+		}
+		return b
+	case []Field:
+		b = cborHead(b, cborMap, uint64(len(val)))
+		for _, field := range val {
+			b = cborAppend(b, field.Key)
+			b = cborAppend(b, field.Value)
+		}
+		return b
+	default:
+		return cborAppend(b, fmt.Sprintf("%v", val))
+	}
+}
+
+// function 'cborDecode' decodes the next data item, maps are decoded as '[]Field' to keep their order,
+// indefinite length items are not supported because 'CBORFormatter' never writes them
+func cborDecode(br *byteReader) (any, error) {
+	if err := br.enter(); err != nil {
+		return nil, err
+	}
+	defer br.leave()
+
+	initial, err := br.byte()
+	if err != nil {
+		return nil, err
+	}
+	major, info := initial&0xe0, initial&0x1f
+
+	if major == cborSimple {
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		case 25:
+			bits, err := br.uint(2)
+			if err != nil {
+				return nil, err
+			}
+			return halfFloat(uint16(bits)), nil
+		case 26:
+			bits, err := br.uint(4)
+			if err != nil {
+				return nil, err
+			}
+			return float64(math.Float32frombits(uint32(bits))), nil
+		case 27:
+			bits, err := br.uint(8)
+			if err != nil {
+				return nil, err
+			}
+			return math.Float64frombits(bits), nil
+		default:
+			return nil, fmt.Errorf("unsupported simple value %d", info)
+		}
+	}
+
+	var n uint64
+	switch {
+	case info < 24:
+		n = uint64(info)
+	case info <= 27:
+		if n, err = br.uint(1 << (info - 24)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported additional information %d", info)
+	}
+
+	switch major {
+	case cborUint:
+		return decodedInt(n), nil
+	case cborNegint:
+		if n > math.MaxInt64 {
+			return nil, fmt.Errorf("negative integer out of range")
+		}
+		return -1 - int64(n), nil
+	case cborBytes:
+		b, err := br.next(n)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case cborText:
+		b, err := br.next(n)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case cborArray:
+		if n > uint64(len(br.b)) {
+			return nil, errShortPayload
+		}
+		items := make([]any, 0, n)
+		for i := uint64(0); i < n; i++ {
+			item, err := cborDecode(br)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case cborMap:
+		if n > uint64(len(br.b)) {
+			return nil, errShortPayload
+		}
+		fields := make([]Field, 0, n)
+		for i := uint64(0); i < n; i++ {
+			key, err := cborDecode(br)
+			if err != nil {
+				return nil, err
+			}
+			value, err := cborDecode(br)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, Field{Key: fmt.Sprint(key), Value: value})
+		}
+		return fields, nil
+	default:
+		content, err := cborDecode(br)
+		if err != nil {
+			return nil, err
+		}
+		if n == cborTagDateTime {
+			text, ok := content.(string)
+			if !ok {
+				return nil, fmt.Errorf("date/time tag on %T", content)
+			}
+			return time.Parse(time.RFC3339Nano, text)
+		}
+		// unknown tags are ignored and their content is returned as is
+		return content, nil
+	}
+}
+
+// function 'halfFloat' converts an IEEE 754 half precision number to float64
+func halfFloat(bits uint16) float64 {
+	exp := int(bits>>10) & 0x1f
+	mant := float64(bits & 0x3ff)
+	var v float64
+	switch exp {
+	case 0:
+		v = mant * math.Pow(2, -24)
+	case 31:
+		if mant == 0 {
+			v = math.Inf(1)
+		} else {
+			v = math.NaN()
+		}
+	default:
+		v = (mant + 1024) * math.Pow(2, float64(exp-25))
+	}
+	if bits&0x8000 != 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,375 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// struct 'Config' represents a declarative logger configuration,
+// it is loaded with 'LoadConfigEnv' or 'LoadConfigFile' and turned into a logger by 'NewFromConfig',
+// string values may reference environment variables as "${KEY}" or "${KEY:-default}"
+type Config struct {
+	Level        string          `json:"level"`
+	Levels       string          `json:"levels"`
+	Format       string          `json:"format"`
+	Pattern      string          `json:"pattern"`
+	Handlers     []HandlerConfig `json:"handlers"`
+	TraceIdKey   string          `json:"trace_id_key"`
+	BufferSize   int             `json:"buffer_size"`
+	Workers      int             `json:"workers"`
+	Backpressure string          `json:"backpressure"`
+	Service      string          `json:"service"`
+	Environment  string          `json:"environment"`
+}
+
+// struct 'HandlerConfig' represents a handler declared in a 'Config',
+// 'Type' names a factory registered with 'RegisterHandler', e.g. "console" or "file",
+// 'Format' and 'Pattern' override the format of the configuration for this handler only
+type HandlerConfig struct {
+	Type    string `json:"type"`
+	Path    string `json:"path,omitempty"`
+	Format  string `json:"format,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// type 'HandlerFactory' represents a constructor of the handlers declared in a 'Config'
+type HandlerFactory func(c HandlerConfig, f Formatter) (Handler, error)
+
+// variable 'handlerFactories' holds the factories registered with 'RegisterHandler'
+var handlerFactories sync.Map
+
+// function 'RegisterHandler' registers the factory of a handler type usable in a 'Config',
+// the handlers package registers "console" and "file" when it is imported
+func RegisterHandler(kind string, factory HandlerFactory) {
+	handlerFactories.Store(kind, factory)
+}
+
+// function 'LoadConfigEnv' loads a configuration from environment variables with the given prefix,
+// e.g. with the prefix "LOG" the variables are LOG_LEVEL, LOG_LEVELS, LOG_FORMAT, LOG_PATTERN,
+// LOG_HANDLERS (comma separated types), LOG_FILE (path of the file handler), LOG_TRACE_ID_KEY,
+// LOG_BUFFER_SIZE, LOG_WORKERS, LOG_BACKPRESSURE, LOG_SERVICE and LOG_ENVIRONMENT,
+// variables that are not set keep their defaults
+func LoadConfigEnv(prefix string) (Config, error) {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+	env := func(key string) string {
+		return strings.TrimSpace(os.Getenv(prefix + key))
+	}
+
+	c := Config{
+		Level:        env("LEVEL"),
+		Levels:       env("LEVELS"),
+		Format:       env("FORMAT"),
+		Pattern:      env("PATTERN"),
+		TraceIdKey:   env("TRACE_ID_KEY"),
+		Backpressure: env("BACKPRESSURE"),
+		Service:      env("SERVICE"),
+		Environment:  env("ENVIRONMENT"),
+	}
+
+	var errs []error
+	for _, v := range []struct {
+		key string
+		dst *int
+	}{{"BUFFER_SIZE", &c.BufferSize}, {"WORKERS", &c.Workers}} {
+		if val := env(v.key); val != "" {
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s%s: %q is not an integer", prefix, v.key, val))
+				continue
+			}
+			*v.dst = n
+		}
+	}
+
+	for _, kind := range strings.Split(env("HANDLERS"), ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			c.Handlers = append(c.Handlers, HandlerConfig{Type: kind})
+		}
+	}
+	if path := env("FILE"); path != "" {
+		found := false
+		for i := range c.Handlers {
+			if c.Handlers[i].Type == "file" {
+				c.Handlers[i].Path, found = path, true
+			}
+		}
+		if !found {
+			if len(c.Handlers) == 0 {
+				c.Handlers = append(c.Handlers, HandlerConfig{Type: "console"})
+			}
+			c.Handlers = append(c.Handlers, HandlerConfig{Type: "file", Path: path})
+		}
+	}
+
+	if len(errs) > 0 {
+		return Config{}, fmt.Errorf("logger config: %w", errors.Join(errs...))
+	}
+	return c, nil
+}
+
+// function 'LoadConfigFile' loads a configuration from a file,
+// files ending in ".json" are decoded as JSON and other files as the YAML-like format read by 'parseConfigText',
+// unknown keys are rejected so typos do not silently fall back to defaults
+func LoadConfigFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("logger config: %w", err)
+	}
+
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		doc, err := parseConfigText(data)
+		if err != nil {
+			return Config{}, fmt.Errorf("logger config %s: %w", path, err)
+		}
+		if err := typeConfigValues(doc, reflect.TypeOf(Config{})); err != nil {
+			return Config{}, fmt.Errorf("logger config %s: %w", path, err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return Config{}, fmt.Errorf("logger config %s: %w", path, err)
+		}
+	}
+
+	var c Config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return Config{}, fmt.Errorf("logger config %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// function 'Validate' checks the configuration and returns all problems found,
+// environment variable references are expected to be expanded already, see 'NewFromConfig'
+func (c Config) Validate() error {
+	var errs []error
+	if c.Level != "" {
+		if _, err := ParseLevel(c.Level); err != nil {
+			errs = append(errs, fmt.Errorf("level: %w", err))
+		}
+	}
+	if c.Levels != "" {
+		if _, err := ParseLevelOverrides(c.Levels); err != nil {
+			errs = append(errs, fmt.Errorf("levels: %w", err))
+		}
+	}
+	if _, err := newConfigFormatter(c.Format, c.Pattern); err != nil {
+		errs = append(errs, err)
+	}
+	if c.BufferSize < 0 {
+		errs = append(errs, fmt.Errorf("buffer_size: %d must not be negative", c.BufferSize))
+	}
+	if c.Workers < 0 {
+		errs = append(errs, fmt.Errorf("workers: %d must not be negative", c.Workers))
+	}
+	if _, err := parseBackpressure(c.Backpressure); err != nil {
+		errs = append(errs, err)
+	}
+
+	for i, h := range c.Handlers {
+		if h.Type == "" {
+			errs = append(errs, fmt.Errorf("handlers[%d]: type is required", i))
+			continue
+		}
+		if _, ok := handlerFactories.Load(h.Type); !ok {
+			errs = append(errs, fmt.Errorf("handlers[%d]: unknown type %q, is the package registering it imported?", i, h.Type))
+		}
+		if h.Type == "file" && h.Path == "" {
+			errs = append(errs, fmt.Errorf("handlers[%d]: file handler requires a path", i))
+		}
+		if h.Format != "" || h.Pattern != "" {
+			if _, err := newConfigFormatter(h.formatOr(c), h.patternOr(c)); err != nil {
+				errs = append(errs, fmt.Errorf("handlers[%d]: %w", i, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// function 'NewFromConfig' creates a new logger from the given configuration,
+// environment variable references are expanded first and variables that are not set are reported
+// by name instead of ending up as placeholders in the logs,
+// a configuration without handlers writes to the console, the given options are applied last
+func NewFromConfig(c Config, opts ...Option) (*Logger, error) {
+	l, _, err := newFromConfig(c, opts)
+	return l, err
+}
+
+// function 'newFromConfig' creates a new logger from the given configuration,
+// it also returns the handlers created from the configuration
+func newFromConfig(c Config, opts []Option) (*Logger, []Handler, error) {
+	c, err := c.prepare()
+	if err != nil {
+		return nil, nil, err
+	}
+	handlers, err := c.newHandlers()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	options := make([]Option, 0, len(handlers)+len(opts)+9)
+	for _, h := range handlers {
+		options = append(options, WithHandler(h))
+	}
+	if c.Level != "" {
+		level, _ := ParseLevel(c.Level)
+		options = append(options, WithLevel(level))
+	}
+	if c.Levels != "" {
+		options = append(options, WithLevelOverrides(c.Levels))
+	}
+	if c.TraceIdKey != "" {
+		options = append(options, WithTraceIdKey(c.TraceIdKey))
+	}
+	if c.Service != "" {
+		options = append(options, WithService(c.Service))
+	}
+	if c.Environment != "" {
+		options = append(options, WithEnvironment(c.Environment))
+	}
+	backpressure, _ := parseBackpressure(c.Backpressure)
+	options = append(options,
+		WithBufferSize(c.BufferSize),
+		WithWorkers(c.Workers),
+		WithBackpressure(backpressure),
+	)
+	options = append(options, opts...)
+
+	return NewLogger(options...), handlers, nil
+}
+
+// function 'prepare' expands the environment variable references of the configuration and validates it
+func (c Config) prepare() (Config, error) {
+	missing := map[string]bool{}
+	c = c.expand(missing)
+	if err := errors.Join(missingEnvError(missing), c.Validate()); err != nil {
+		return Config{}, fmt.Errorf("logger config: %w", err)
+	}
+	return c, nil
+}
+
+// function 'newHandlers' creates the handlers declared in a prepared configuration,
+// a configuration without handlers writes to the console,
+// if a handler cannot be created, the handlers created so far are closed
+func (c Config) newHandlers() ([]Handler, error) {
+	configs := c.Handlers
+	if len(configs) == 0 {
+		configs = []HandlerConfig{{Type: "console"}}
+	}
+
+	handlers := make([]Handler, 0, len(configs))
+	for i, h := range configs {
+		handler, err := c.newHandler(h)
+		if err != nil {
+			closeHandlers(handlers)
+			return nil, fmt.Errorf("logger config: handlers[%d]: %w", i, err)
+		}
+		handlers = append(handlers, handler)
+	}
+	return handlers, nil
+}
+
+// function 'newHandler' creates a single handler with the factory registered for its type
+func (c Config) newHandler(h HandlerConfig) (Handler, error) {
+	factory, ok := handlerFactories.Load(h.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q, is the package registering it imported?", h.Type)
+	}
+	formatter, err := newConfigFormatter(h.formatOr(c), h.patternOr(c))
+	if err != nil {
+		return nil, err
+	}
+	return factory.(HandlerFactory)(h, formatter)
+}
+
+// function 'closeHandlers' closes the given handlers that hold resources, e.g. open files
+func closeHandlers(handlers []Handler) error {
+	var errs []error
+	for _, h := range handlers {
+		if closer, ok := h.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("closing handler %T: %w", h, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// function 'expand' returns a copy of the configuration with environment variable references expanded
+func (c Config) expand(missing map[string]bool) Config {
+	for _, s := range []*string{&c.Level, &c.Levels, &c.Format, &c.Pattern, &c.TraceIdKey, &c.Backpressure, &c.Service, &c.Environment} {
+		*s = expandEnv(*s, missing)
+	}
+	handlers := make([]HandlerConfig, len(c.Handlers))
+	for i, h := range c.Handlers {
+		for _, s := range []*string{&h.Type, &h.Path, &h.Format, &h.Pattern} {
+			*s = expandEnv(*s, missing)
+		}
+		handlers[i] = h
+	}
+	c.Handlers = handlers
+	return c
+}
+
+// function 'formatOr' returns the format of the handler or the format of the configuration
+func (h HandlerConfig) formatOr(c Config) string {
+	if h.Format != "" {
+		return h.Format
+	}
+	return c.Format
+}
+
+// function 'patternOr' returns the pattern of the handler or the pattern of the configuration
+func (h HandlerConfig) patternOr(c Config) string {
+	if h.Pattern != "" {
+		return h.Pattern
+	}
+	return c.Pattern
+}
+
+// function 'newConfigFormatter' creates the formatter of a configured format,
+// the format is one of "json", "text" using the pattern, "logfmt" or "dev",
+// it defaults to "text" when a pattern is given and to "json" otherwise
+func newConfigFormatter(format, pattern string) (Formatter, error) {
+	if format == "" && pattern != "" {
+		format = "text"
+	}
+	switch strings.ToLower(format) {
+	case "", "json":
+		return NewJSONFormatter(), nil
+	case "text":
+		f, err := NewTextFormatter(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("pattern: %w", err)
+		}
+		return f, nil
+	case "logfmt":
+		return NewLogfmtFormatter(), nil
+	case "dev":
+		return NewDevFormatter(), nil
+	default:
+		return nil, fmt.Errorf("format: unknown format %q, expected json, text, logfmt or dev", format)
+	}
+}
+
+// function 'parseBackpressure' parses the name of a backpressure strategy, empty means 'Drop'
+func parseBackpressure(name string) (BackpressureStrategy, error) {
+	switch strings.ToLower(name) {
+	case "", "drop":
+		return Drop, nil
+	case "block":
+		return Block, nil
+	default:
+		return Drop, fmt.Errorf("backpressure: unknown strategy %q, expected drop or block", name)
+	}
+}
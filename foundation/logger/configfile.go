@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// function 'parseConfigText' parses the YAML-like configuration format, a subset of YAML made of
+// "key: value" lines at the top level and lists of "key: value" items, e.g.
+//
+//	level: debug
+//	service: ${SERVICE_NAME}
+//	handlers:
+//	  - type: console
+//	  - type: file
+//	    path: /var/log/app.log
+//
+// comments start with '#', values may be quoted and are read as strings,
+// 'typeConfigValues' converts them to the types of the fields they are decoded into
+func parseConfigText(data []byte) (map[string]any, error) {
+	doc := map[string]any{}
+	var list []any
+	var listKey string
+	var item map[string]any
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := stripConfigComment(scanner.Text())
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indented := line[0] == ' ' || line[0] == '\t'
+		line = strings.TrimSpace(line)
+
+		if !indented {
+			if listKey != "" {
+				doc[listKey] = list
+				listKey, list, item = "", nil, nil
+			}
+			key, value, err := splitConfigLine(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			if value == "" {
+				// a key without value starts a list
+				listKey, list = key, []any{}
+				continue
+			}
+			doc[key] = configValue(value)
+			continue
+		}
+
+		if listKey == "" {
+			return nil, fmt.Errorf("line %d: unexpected indentation", n)
+		}
+		if rest, ok := strings.CutPrefix(line, "-"); ok {
+			item = map[string]any{}
+			list = append(list, item)
+			line = strings.TrimSpace(rest)
+			if line == "" {
+				continue
+			}
+		}
+		if item == nil {
+			return nil, fmt.Errorf("line %d: list item must start with '-'", n)
+		}
+		key, value, err := splitConfigLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		item[key] = configValue(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if listKey != "" {
+		doc[listKey] = list
+	}
+	return doc, nil
+}
+
+// function 'splitConfigLine' splits a "key: value" line
+func splitConfigLine(line string) (string, string, error) {
+	key, value, ok := strings.Cut(line, ":")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("expected \"key: value\", got %q", line)
+	}
+	return key, strings.TrimSpace(value), nil
+}
+
+// function 'stripConfigComment' removes a comment starting with '#' outside of quotes
+func stripConfigComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// function 'configValue' decodes a scalar value by removing its quotes
+func configValue(value string) any {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		if value[0] == '"' {
+			if s, err := strconv.Unquote(value); err == nil {
+				return s
+			}
+		}
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// function 'typeConfigValues' converts the string values of a parsed document to the kinds of the
+// struct fields they are decoded into, matched by json tag, so "workers: 4" becomes a number
+// while "service: 123" stays a string, keys without a field are left for the decoder to reject
+func typeConfigValues(doc map[string]any, t reflect.Type) error {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); name != "" && name != "-" {
+			fields[name] = f.Type
+		}
+	}
+
+	for key, value := range doc {
+		ft, ok := fields[key]
+		if !ok {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			typed, err := typeConfigScalar(v, ft.Kind())
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			doc[key] = typed
+		case []any:
+			if ft.Kind() != reflect.Slice || ft.Elem().Kind() != reflect.Struct {
+				continue
+			}
+			for i, item := range v {
+				if m, ok := item.(map[string]any); ok {
+					if err := typeConfigValues(m, ft.Elem()); err != nil {
+						return fmt.Errorf("%s[%d].%w", key, i, err)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// function 'typeConfigScalar' converts a string value to the given kind, other kinds keep the string
+func typeConfigScalar(value string, kind reflect.Kind) (any, error) {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", value)
+		}
+		return n, nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", value)
+		}
+		return b, nil
+	default:
+		return value, nil
+	}
+}
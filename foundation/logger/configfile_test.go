@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigFileTypesValuesByField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logger.yaml")
+	data := "service: 123\nenvironment: '007'\nbuffer_size: 10\nhandlers:\n  - type: file\n    path: 2024\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Service != "123" || c.Environment != "007" || c.BufferSize != 10 {
+		t.Errorf("got service %q, environment %q and buffer size %d", c.Service, c.Environment, c.BufferSize)
+	}
+	if len(c.Handlers) != 1 || c.Handlers[0].Path != "2024" {
+		t.Errorf("got handlers %+v", c.Handlers)
+	}
+}
+
+func TestLoadConfigFileRejectsNonNumericInteger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logger.yaml")
+	if err := os.WriteFile(path, []byte("workers: many\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfigFile(path); err == nil {
+		t.Fatal("expected an error for a non numeric worker count")
+	}
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"context"
+	"sync"
+)
+
+// struct 'loggerCtxKey' is the key of the logger stored in a context
+type loggerCtxKey struct{}
+
+// struct 'fieldsCtxKey' is the key of the fields stored in a context
+type fieldsCtxKey struct{}
+
+// variable 'discardLogger' is the logger returned by 'FromContext' in case of missing logger,
+// it has no handlers so every record is discarded
+var discardLogger = sync.OnceValue(func() *Logger {
+	return NewLogger()
+})
+
+// function 'ContextWithLogger' returns a copy of the context carrying the given logger,
+// it is used to pass a request scoped logger down the call stack
+func ContextWithLogger(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerCtxKey{}, l)
+}
+
+// function 'FromContext' returns the logger carried by the context,
+// if the context carries no logger, it returns a logger discarding all records
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerCtxKey{}).(*Logger); ok && l != nil {
+			return l
+		}
+	}
+	return discardLogger()
+}
+
+// function 'ContextWithFields' returns a copy of the context carrying the given fields
+// in addition to the fields already carried by it,
+// the fields are added to every record logged with the context by the '*Ctx' methods,
+// they take precedence over 'Logger.Fields' while the fields of the call take precedence over them
+func ContextWithFields(ctx context.Context, fields ...Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	existing := FieldsFromContext(ctx)
+	merged := make([]Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsCtxKey{}, merged)
+}
+
+// function 'FieldsFromContext' returns the fields carried by the context
+func FieldsFromContext(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsCtxKey{}).([]Field)
+	return fields
+}
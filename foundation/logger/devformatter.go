@@ -0,0 +1,278 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// constants for ANSI escape sequences used by 'DevFormatter'
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiDim     = "\x1b[2m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+	ansiBlue    = "\x1b[34m"
+	ansiMagenta = "\x1b[35m"
+)
+
+// constant 'defaultMessageWidth' is the column width messages are padded to in case of missing width
+const defaultMessageWidth = 40
+
+// struct 'DevFormatter' implements 'Formatter' interface,
+// it renders records for humans reading a terminal during local development,
+// 'RelativeTime' prints the time elapsed since the formatter was created instead of the wall clock,
+// 'MessageWidth' is the column width messages are padded to so fields line up
+type DevFormatter struct {
+	Color        bool
+	RelativeTime bool
+	MessageWidth int
+	start        time.Time
+}
+
+// function 'NewDevFormatter' creates a new 'DevFormatter',
+// color is enabled when stdout is a terminal, see 'ColorEnabled'
+func NewDevFormatter() *DevFormatter {
+	return &DevFormatter{
+		Color:        ColorEnabled(os.Stdout),
+		MessageWidth: defaultMessageWidth,
+		start:        time.Now(),
+	}
+}
+
+// function 'ColorEnabled' reports whether colored output should be written to the given file,
+// a non empty 'NO_COLOR' disables color, a 'FORCE_COLOR' other than "0" or "false" enables it,
+// otherwise color is enabled only when the file is a terminal
+func ColorEnabled(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if force := os.Getenv("FORCE_COLOR"); force != "" {
+		return force != "0" && force != "false"
+	}
+	if f == nil {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// function 'Format' formats the given record as an aligned, optionally colored line,
+// multi-line values and errors are printed below the line as indented blocks
+func (f *DevFormatter) Format(r Record) []byte {
+	var buf bytes.Buffer
+
+	f.paint(&buf, ansiDim, f.timestamp(r.Timestamp))
+	buf.WriteByte(' ')
+	f.paint(&buf, levelColor(r.Level), levelTag(r.Level))
+	buf.WriteByte(' ')
+
+	width := f.MessageWidth
+	if width <= 0 {
+		width = defaultMessageWidth
+	}
+	f.paint(&buf, ansiBold, r.Message)
+	if pad := width - utf8.RuneCountInString(r.Message); pad > 0 && (len(r.Fields) > 0 || r.Caller != "") {
+		buf.WriteString(strings.Repeat(" ", pad))
+	}
+
+	var blocks []devBlock
+	for _, field := range r.Fields {
+		if block, ok := blockValue(field); ok {
+			blocks = append(blocks, block)
+			continue
+		}
+		buf.WriteByte(' ')
+		f.paint(&buf, ansiDim, field.Key+"=")
+		if err, ok := field.Value.(error); ok {
+			f.paint(&buf, ansiRed, inlineValue(err.Error()))
+			continue
+		}
+		buf.WriteString(inlineValue(field.Value))
+	}
+
+	if r.TraceId != "" && r.TraceId != defaultTraceIdValue {
+		buf.WriteByte(' ')
+		f.paint(&buf, ansiDim, "trace_id="+r.TraceId)
+	}
+	if r.Caller != "" {
+		buf.WriteByte(' ')
+		f.paint(&buf, ansiDim, "("+r.Caller+")")
+	}
+	buf.WriteByte('\n')
+
+	if len(r.Stack) > 0 {
+		blocks = append(blocks, devBlock{key: "stacktrace", text: StackString(r.Stack)})
+	}
+	for _, block := range blocks {
+		color := ansiDim
+		if block.err {
+			color = ansiRed
+		}
+		buf.WriteString("    ")
+		f.paint(&buf, color, block.key+":")
+		buf.WriteByte('\n')
+		for _, line := range strings.Split(strings.TrimRight(block.text, "\n"), "\n") {
+			buf.WriteString("      ")
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// function 'timestamp' returns the clock time or the time elapsed since the formatter was created
+func (f *DevFormatter) timestamp(t time.Time) string {
+	if f.RelativeTime {
+		start := f.start
+		if start.IsZero() {
+			start = t
+		}
+		return fmt.Sprintf("+%9.3fs", t.Sub(start).Seconds())
+	}
+	return t.Format("15:04:05.000")
+}
+
+// function 'paint' writes the given text wrapped in the given color when color is enabled
+func (f *DevFormatter) paint(buf *bytes.Buffer, color, text string) {
+	if !f.Color {
+		buf.WriteString(text)
+		return
+	}
+	buf.WriteString(color)
+	buf.WriteString(text)
+	buf.WriteString(ansiReset)
+}
+
+// function 'levelTag' returns the fixed width tag of the given level
+func levelTag(level Level) string {
+	switch level {
+	case Debug:
+		return "DBG"
+	case Info:
+		return "INF"
+	case Warn:
+		return "WRN"
+	case Error:
+		return "ERR"
+	case Audit:
+		return "AUD"
+	default:
+		return "???"
+	}
+}
+
+// function 'levelColor' returns the color of the given level
+func levelColor(level Level) string {
+	switch level {
+	case Debug:
+		return ansiMagenta
+	case Info:
+		return ansiGreen
+	case Warn:
+		return ansiYellow
+	case Error:
+		return ansiRed
+	default:
+		return ansiBlue
+	}
+}
+
+// struct 'devBlock' represents a field value printed as an indented block below the record line
+type devBlock struct {
+	key  string
+	text string
+	err  bool
+}
+
+// function 'blockValue' returns the block of fields that are printed below the record line,
+// these are errors wrapping other errors or carrying a stack and strings spanning multiple lines
+func blockValue(field Field) (devBlock, bool) {
+	switch val := field.Value.(type) {
+	case ErrorValue:
+		return errorBlock(field.Key, val.Err)
+	case error:
+		// '%+v' includes the stack trace for errors that carry one
+		if text := fmt.Sprintf("%+v", val); strings.Contains(text, "\n") && text != val.Error() {
+			return devBlock{key: field.Key, text: text, err: true}, true
+		}
+		return errorBlock(field.Key, val)
+	case string:
+		if strings.Contains(val, "\n") {
+			return devBlock{key: field.Key, text: val}, true
+		}
+	}
+	return devBlock{}, false
+}
+
+// function 'errorBlock' returns the block of an error listing its causes and stack,
+// errors rendered on a single line are printed inline
+func errorBlock(key string, err error) (devBlock, bool) {
+	var b strings.Builder
+	writeErrorChain(&b, err, "", "")
+	if stack := errorStack(err); len(stack) > 0 {
+		b.WriteString(StackString(stack))
+	}
+	text := strings.TrimRight(b.String(), "\n")
+	if !strings.Contains(text, "\n") {
+		return devBlock{}, false
+	}
+	return devBlock{key: key, text: text, err: true}, true
+}
+
+// function 'writeErrorChain' writes the message of an error followed by its unwrapped causes, one per line,
+// errors joined by 'errors.Join' are listed below as nested chains,
+// 'first' prefixes the first line and 'indent' the following ones
+func writeErrorChain(b *strings.Builder, err error, first, indent string) {
+	label := first
+	for i := 0; err != nil && i < maxErrorChain; i++ {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			errs := joined.Unwrap()
+			fmt.Fprintf(b, "%s%d joined errors\n", label, len(errs))
+			for _, e := range errs {
+				if e != nil {
+					writeErrorChain(b, e, indent+"  - ", indent+"    ")
+				}
+			}
+			return
+		}
+		b.WriteString(label)
+		b.WriteString(strings.ReplaceAll(err.Error(), "\n", "\n"+indent))
+		b.WriteByte('\n')
+		label = indent + "caused by: "
+		err = errors.Unwrap(err)
+	}
+}
+
+// function 'inlineValue' returns the single line representation of a field value,
+// strings with spaces are quoted and composite values are encoded as JSON
+func inlineValue(v any) string {
+	switch val := v.(type) {
+	case string:
+		if val == "" || strings.ContainsAny(val, " \t\"=") {
+			return fmt.Sprintf("%q", val)
+		}
+		return val
+	case fmt.Stringer:
+		return val.String()
+	case []Field:
+		return inlineValue(GroupMap(val))
+	case map[string]any, []any:
+		b, err := json.Marshal(val)
+		if err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprintf("%v", v)
+}
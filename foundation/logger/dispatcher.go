@@ -25,6 +25,7 @@ const (
 type Dispatcher struct {
 	records              chan dispatchEntry
 	wg                   sync.WaitGroup
+	handlersMu           sync.RWMutex
 	handlers             []Handler
 	hooks                []Hook
 	numWorkers           int
@@ -33,13 +34,19 @@ type Dispatcher struct {
 	internalErrorHandler func(error)
 	dropNoticeThreshold  int64
 	droppedLogsCount     int64
+	errorLimiter         *errorLimiter
+	wal                  *wal
+	synchronous          bool
+	closeOnce            sync.Once
 }
 
 // struct 'dispatchEntry' represents a dispatch entry,
-// it contains a context and a structured logging record
+// it contains a context, a structured logging record and the write-ahead log segment and index of the record
 type dispatchEntry struct {
 	ctx context.Context
 	rec Record
+	seg *walSegment
+	idx int
 }
 
 // function 'NewDispatcher' creates a new dispatcher instance
@@ -67,6 +74,7 @@ func NewDispatcher(
 		bufferSize:           bufferSize,
 		dropNoticeThreshold:  1000,
 		internalErrorHandler: internalErrorHandler,
+		errorLimiter:         newErrorLimiter(10, time.Second),
 	}
 
 	for i := 0; i < numWorkers; i++ {
@@ -77,15 +85,49 @@ func NewDispatcher(
 	return d
 }
 
-// function 'Dispatch' dispatches a structured logging record to the dispatcher
+// function 'NewSyncDispatcher' creates a new dispatcher delivering every record on the goroutine dispatching it,
+// records are neither queued nor dropped, which makes the output of tests deterministic
+func NewSyncDispatcher(handlers []Handler, hooks []Hook, internalErrorHandler func(error)) *Dispatcher {
+	return &Dispatcher{
+		records:              make(chan dispatchEntry),
+		handlers:             handlers,
+		hooks:                hooks,
+		backpressure:         Block,
+		dropNoticeThreshold:  1000,
+		internalErrorHandler: internalErrorHandler,
+		errorLimiter:         newErrorLimiter(10, time.Second),
+		synchronous:          true,
+	}
+}
+
+// function 'Dispatch' dispatches a structured logging record to the dispatcher,
+// 'Audit' records are never dropped, they block until there is room in the buffer,
+// with a write-ahead log the record is appended to it before it is queued
 func (d *Dispatcher) Dispatch(ctx context.Context, rec Record) {
 	entry := dispatchEntry{ctx: ctx, rec: rec}
+	if d.wal != nil {
+		seg, idx, err := d.wal.append(rec)
+		if err != nil {
+			d.reportInternalError(err)
+		}
+		entry.seg, entry.idx = seg, idx
+	}
+	if d.synchronous {
+		d.deliver(ctx, rec)
+		d.ack(entry)
+		return
+	}
 
-	switch d.backpressure {
+	backpressure := d.backpressure
+	if rec.Level == Audit {
+		backpressure = Block
+	}
+	switch backpressure {
 	case Drop:
 		select {
 		case d.records <- entry:
 		default:
+			d.ack(entry)
 			atomic.AddInt64(&d.droppedLogsCount, 1)
 			if d.DroppedCount()%d.dropNoticeThreshold == 0 {
 				d.reportInternalError(fmt.Errorf("dropped %d logs due to full queue", d.DroppedCount()))
@@ -96,15 +138,50 @@ func (d *Dispatcher) Dispatch(ctx context.Context, rec Record) {
 	}
 }
 
+// function 'Close' delivers the queued records and stops the workers, calls after the first one are no-ops
 func (d *Dispatcher) Close() {
-	close(d.records)
-	d.wg.Wait()
+	d.closeOnce.Do(func() {
+		close(d.records)
+		d.wg.Wait()
+		if d.wal != nil {
+			d.wal.close()
+		}
+	})
 }
 
 func (d *Dispatcher) run() {
 	defer d.wg.Done()
 	for entry := range d.records {
 		d.deliver(entry.ctx, entry.rec)
+		d.ack(entry)
+	}
+}
+
+// function 'OpenWAL' makes the dispatcher append every record to a write-ahead log before queueing it,
+// the records left by a previous run that were not acknowledged, e.g. because the process crashed,
+// are delivered first with the field "replayed" set, so delivery is at-least-once and a record may be
+// delivered twice when the process stopped during its delivery, when records were acknowledged out of order
+// or when the host failed before the acknowledgements reached the disk, it must be called before the first dispatch,
+// a record is acknowledged once the handlers returned, whether they succeeded or not, and dropped records
+// are acknowledged when they are dropped
+func (d *Dispatcher) OpenWAL(config WALConfig) error {
+	w, recovered, err := openWAL(config, d.reportInternalError)
+	if err != nil {
+		return err
+	}
+	for _, rec := range recovered {
+		rec.Fields = append(rec.Fields, Bool("replayed", true))
+		d.deliver(context.Background(), rec)
+	}
+	w.removeReplayed()
+	d.wal = w
+	return nil
+}
+
+// function 'ack' acknowledges the record of an entry to the write-ahead log
+func (d *Dispatcher) ack(entry dispatchEntry) {
+	if entry.seg != nil {
+		d.wal.ack(entry.seg, entry.idx)
 	}
 }
 
@@ -113,12 +190,13 @@ func (d *Dispatcher) deliver(ctx context.Context, rec Record) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
+	d.handlersMu.RLock()
 	for _, h := range d.handlers {
-		func() {
-			defer d.recover()
-			h.Handle(ctx, rec)
-		}()
+		if err := d.handle(ctx, h, rec); err != nil {
+			d.reportInternalError(&HandlerError{Handler: h, Record: rec, Err: err})
+		}
 	}
+	d.handlersMu.RUnlock()
 
 	for _, hook := range d.hooks {
 		func() {
@@ -132,15 +210,38 @@ func (d *Dispatcher) deliver(ctx context.Context, rec Record) {
 				hook.OnWarn(ctx, rec)
 			case Error:
 				hook.OnError(ctx, rec)
+			case Audit:
+				// audit records belong to the audit trail and are not routed to the level hooks
 			}
 		}()
 	}
 }
 
-// function 'recover' recovers from a panic in a handler or hook
+// function 'SetHandlers' replaces the handlers of the dispatcher and returns the previous ones,
+// records still queued are delivered to the new handlers and no record is delivered to a mix of both,
+// it waits for deliveries in progress so the previous handlers can be closed once it returns
+func (d *Dispatcher) SetHandlers(handlers []Handler) []Handler {
+	d.handlersMu.Lock()
+	defer d.handlersMu.Unlock()
+	old := d.handlers
+	d.handlers = handlers
+	return old
+}
+
+// function 'handle' hands a record to a single handler and converts a panic into an error
+func (d *Dispatcher) handle(ctx context.Context, h Handler, rec Record) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("recovered from panic: %v", p)
+		}
+	}()
+	return h.Handle(ctx, rec)
+}
+
+// function 'recover' recovers from a panic in a hook
 func (d *Dispatcher) recover() {
 	if r := recover(); r != nil {
-		d.reportInternalError(fmt.Errorf("recovered from panic in handler/hook: %v", r))
+		d.reportInternalError(fmt.Errorf("recovered from panic in hook: %v", r))
 	}
 }
 
@@ -156,8 +257,22 @@ func (d *Dispatcher) BufferSize() int {
 	return d.bufferSize
 }
 
-// function 'reportInternalError' reports an internal error
+// function 'reportInternalError' reports an internal error,
+// errors are rate limited so a persistently failing handler does not flood the error output,
+// the number of suppressed errors is reported once reporting is allowed again
 func (d *Dispatcher) reportInternalError(err error) {
+	allowed, suppressed := d.errorLimiter.allow()
+	if !allowed {
+		return
+	}
+	if suppressed > 0 {
+		d.emitInternalError(fmt.Errorf("suppressed %d internal errors", suppressed))
+	}
+	d.emitInternalError(err)
+}
+
+// function 'emitInternalError' hands an internal error to the internal error handler
+func (d *Dispatcher) emitInternalError(err error) {
 	if d.internalErrorHandler != nil {
 		d.internalErrorHandler(err)
 	} else {
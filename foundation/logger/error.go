@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+)
+
+// constant 'maxErrorChain' is the maximum number of unwrapped causes rendered for an error,
+// it protects formatters against errors whose 'Unwrap' never returns nil
+const maxErrorChain = 32
+
+// variable 'reservedErrorKeys' are the keys of 'ErrorObject' that fields of 'ErrorFields' cannot overwrite
+var reservedErrorKeys = map[string]bool{
+	"message":    true,
+	"type":       true,
+	"causes":     true,
+	"errors":     true,
+	"stacktrace": true,
+}
+
+// type 'StackTracer' represents an error carrying the stack trace of where it was created,
+// the stack of the innermost error of the chain implementing it is rendered by the 'Err' field
+type StackTracer interface {
+	StackTrace() []StackFrame
+}
+
+// type 'ErrorFields' represents an error contributing its own fields to the 'Err' field,
+// e.g. a domain error reporting the id of the entity that failed
+type ErrorFields interface {
+	ErrorFields() []Field
+}
+
+// struct 'ErrorValue' represents the value of a field created by 'Err',
+// formatters render it as an object while it still reads as the wrapped error everywhere else
+type ErrorValue struct {
+	Err error
+}
+
+// function 'Error' returns the message of the wrapped error
+func (v ErrorValue) Error() string {
+	return v.Err.Error()
+}
+
+// function 'Unwrap' returns the wrapped error so 'errors.Is' and 'errors.As' see through the value
+func (v ErrorValue) Unwrap() error {
+	return v.Err
+}
+
+// function 'Object' returns the structured representation of the wrapped error
+func (v ErrorValue) Object() map[string]any {
+	return ErrorObject(v.Err)
+}
+
+// function 'Err' creates an "error" field rendered as an object by the formatters
+func Err(err error) Field {
+	return NamedErr("error", err)
+}
+
+// function 'NamedErr' creates an error field with the given key rendered as an object by the formatters
+func NamedErr(key string, err error) Field {
+	if err == nil {
+		return Field{Key: key, Value: nil}
+	}
+	return Field{Key: key, Value: ErrorValue{Err: err}}
+}
+
+// function 'ErrorObject' returns the structured representation of an error:
+//   - message: the message of the error
+//   - type: the dynamic type of the error, e.g. "*fs.PathError"
+//   - causes: the chain of errors unwrapped by 'errors.Unwrap', outermost first
+//   - errors: the errors joined by 'errors.Join' or wrapped by multiple '%w' verbs
+//   - stacktrace: the stack of the innermost error implementing 'StackTracer'
+//
+// fields of errors implementing 'ErrorFields' are added next to these keys,
+// fields of outer errors take precedence and the keys above are never overwritten
+func ErrorObject(err error) map[string]any {
+	obj := errorEntry(err)
+
+	chain := []error{err}
+	for cause := errors.Unwrap(err); cause != nil && len(chain) <= maxErrorChain; cause = errors.Unwrap(cause) {
+		chain = append(chain, cause)
+	}
+	if len(chain) > 1 {
+		causes := make([]any, 0, len(chain)-1)
+		for _, cause := range chain[1:] {
+			causes = append(causes, errorEntry(cause))
+		}
+		obj["causes"] = causes
+	}
+	if stack := errorStack(err); len(stack) > 0 {
+		obj["stacktrace"] = StackString(stack)
+	}
+
+	// inner errors are visited first so the fields of outer errors overwrite theirs
+	for i := len(chain) - 1; i >= 0; i-- {
+		fielder, ok := chain[i].(ErrorFields)
+		if !ok {
+			continue
+		}
+		for _, field := range fielder.ErrorFields() {
+			if !reservedErrorKeys[field.Key] {
+				obj[field.Key] = jsonValue(field.Value)
+			}
+		}
+	}
+	return obj
+}
+
+// function 'errorEntry' returns the message and type of a single error,
+// joined errors are rendered as objects of their own under "errors"
+func errorEntry(err error) map[string]any {
+	entry := map[string]any{
+		"message": err.Error(),
+		"type":    fmt.Sprintf("%T", err),
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs := make([]any, 0)
+		for _, e := range joined.Unwrap() {
+			if e != nil {
+				errs = append(errs, ErrorObject(e))
+			}
+		}
+		entry["errors"] = errs
+	}
+	return entry
+}
+
+// function 'errorStack' returns the stack of the innermost error implementing 'StackTracer'
+func errorStack(err error) []StackFrame {
+	var stack []StackFrame
+	for i := 0; err != nil && i <= maxErrorChain; i++ {
+		if tracer, ok := err.(StackTracer); ok {
+			stack = tracer.StackTrace()
+		}
+		err = errors.Unwrap(err)
+	}
+	return stack
+}
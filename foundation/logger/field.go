@@ -25,3 +25,69 @@ func Bool(key string, val bool) Field {
 func Map(key string, val map[string]any) Field {
 	return Field{Key: key, Value: val}
 }
+
+// function 'Group' creates a field that nests the given fields under the given key
+func Group(key string, fields ...Field) Field {
+	return Field{Key: key, Value: fields}
+}
+
+// constant 'mergeScanLimit' is the number of fields up to which duplicates are found by scanning instead of a map
+const mergeScanLimit = 16
+
+// function 'mergeFields' concatenates the given lists so that every key appears once,
+// a key keeps the position of its first occurrence and takes the value of its last one
+func mergeFields(lists ...[]Field) []Field {
+	n := 0
+	for _, list := range lists {
+		n += len(list)
+	}
+	merged := make([]Field, 0, n)
+
+	var index map[string]int
+	if n > mergeScanLimit {
+		index = make(map[string]int, n)
+	}
+	for _, list := range lists {
+		for _, field := range list {
+			if i := fieldIndex(merged, index, field.Key); i >= 0 {
+				merged[i] = field
+				continue
+			}
+			if index != nil {
+				index[field.Key] = len(merged)
+			}
+			merged = append(merged, field)
+		}
+	}
+	return merged
+}
+
+// function 'fieldIndex' returns the position of the field with the given key, -1 when there is none
+func fieldIndex(fields []Field, index map[string]int, key string) int {
+	if index != nil {
+		if i, ok := index[key]; ok {
+			return i
+		}
+		return -1
+	}
+	for i, field := range fields {
+		if field.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// function 'GroupMap' converts the fields of a group into a map, nested groups become nested maps,
+// it is used by sinks that encode groups as JSON objects
+func GroupMap(fields []Field) map[string]any {
+	m := make(map[string]any, len(fields))
+	for _, field := range fields {
+		if group, ok := field.Value.([]Field); ok {
+			m[field.Key] = GroupMap(group)
+			continue
+		}
+		m[field.Key] = field.Value
+	}
+	return m
+}
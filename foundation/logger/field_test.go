@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMergeFieldsKeepsLastValue(t *testing.T) {
+	base := []Field{String("service", "api"), String("user", "base")}
+	ctx := []Field{String("request_id", "r1"), String("user", "ctx")}
+	call := []Field{String("user", "call"), Int("n", 1)}
+
+	got := mergeFields(base, ctx, call)
+	want := []Field{{"service", "api"}, {"user", "call"}, {"request_id", "r1"}, {"n", 1}}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("field %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMergeFieldsManyKeys(t *testing.T) {
+	var base, call []Field
+	for i := 0; i < 2*mergeScanLimit; i++ {
+		base = append(base, Int(fmt.Sprintf("k%d", i), i))
+	}
+	call = append(call, Int("k3", -3), Int("extra", 0))
+
+	got := mergeFields(base, call)
+	if len(got) != len(base)+1 {
+		t.Fatalf("got %d fields, want %d", len(got), len(base)+1)
+	}
+	if got[3] != (Field{"k3", -3}) || got[len(got)-1] != (Field{"extra", 0}) {
+		t.Errorf("got %v", got)
+	}
+}
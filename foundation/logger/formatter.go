@@ -4,9 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"os"
+	"strconv"
 	"sync"
 	"text/template"
-	"time"
 )
 
 // variable 'bufPool' is a pool of bytes buffers to reduce allocations
@@ -17,56 +18,91 @@ var bufPool = sync.Pool{
 }
 
 // type 'Formatter' represents a logging formatter,
-// concrete implementations are 'JSONFormatter' and 'TextFormatter'
+// concrete implementations are 'JSONFormatter', 'TextFormatter', 'DevFormatter' and 'LogfmtFormatter'
 type Formatter interface {
 	Format(r Record) []byte
 }
 
-// struct 'JSONFormatter' implements 'Formatter' interface
-type JSONFormatter struct{}
+// struct 'JSONFormatter' implements 'Formatter' interface,
+// 'Profile' maps the record to the JSON document, see 'ECSProfile', 'GELFProfile' and 'GCPProfile',
+// without a profile the plain JSON format is written with the timestamp encoded by 'Timestamp',
+// 'StackFrames' writes the "stacktrace" of the plain format as an array of frames instead of a string
+type JSONFormatter struct {
+	Profile     JSONProfile
+	Timestamp   TimestampEncoder
+	StackFrames bool
+}
 
-// function 'NewJSONFormatter' creates a new 'JSONFormatter'
+// function 'NewJSONFormatter' creates a new 'JSONFormatter' using the plain JSON format
 func NewJSONFormatter() *JSONFormatter {
 	return &JSONFormatter{}
 }
 
+// function 'NewProfileFormatter' creates a new 'JSONFormatter' using the given profile
+func NewProfileFormatter(profile JSONProfile) *JSONFormatter {
+	return &JSONFormatter{Profile: profile}
+}
+
 // function 'Format' formats the given record as JSON
 func (f *JSONFormatter) Format(r Record) []byte {
-	buf := bufPool.Get().(*bytes.Buffer)
-	buf.Reset()
-	defer bufPool.Put(buf)
-
-	payload := make(map[string]any, len(r.Fields)+4)
-	payload["level"] = r.Level.String()
-	payload["message"] = r.Message
-	payload["caller"] = r.Caller
-	payload["timestamp"] = r.Timestamp.Format(time.RFC3339Nano)
+	profile := f.Profile
+	if profile == nil {
+		profile = TimestampProfile(f.Timestamp)
+	}
 
-	for _, field := range r.Fields {
-		payload[field.Key] = field.Value
+	doc := profile(r)
+	if _, ok := doc["stacktrace"]; ok && f.StackFrames {
+		doc["stacktrace"] = r.Stack
 	}
 
-	b, _ := json.Marshal(payload)
-	buf.Write(b)
-	buf.WriteByte('\n')
-	return buf.Bytes()
+	b, err := json.Marshal(doc)
+	if err != nil {
+		b, _ = json.Marshal(map[string]string{
+			"level":   r.Level.String(),
+			"message": r.Message,
+			"error":   fmt.Sprintf("logger: json encoding error: %v", err),
+		})
+	}
+	return append(b, '\n')
 }
 
 // const 'DefaultPattern' is the default pattern for text formatter
 const DefaultPattern = "{{.timestamp}} [{{.level}}] {{.caller}} {{.message}} - [{{.fields}}]"
 
-// struct 'TextFormatter' implements 'Formatter' interface
+// struct 'TextFormatter' implements 'Formatter' interface,
+// the pattern is a text/template that can use the keys level, message, caller, fields, timestamp,
+// trace_id, time and record, and the functions listed in 'textFuncs',
+// patterns made of plain keys only are rendered by a compiled fast path without text/template,
+// 'Color' enables the 'color' template function, 'Timestamp' encodes the timestamp key,
+// a captured stack trace is written below the line as indented frames
 type TextFormatter struct {
-	tmpl *template.Template
+	Color     bool
+	Timestamp TimestampEncoder
+	compiled  []textSegment
+	templates sync.Pool
 }
 
-// function 'NewTextFormatter' creates a new 'TextFormatter'
-func NewTextFormatter(pattern string) *TextFormatter {
+// function 'NewTextFormatter' creates a new 'TextFormatter',
+// it returns an error if the pattern is not a valid template
+func NewTextFormatter(pattern string) (*TextFormatter, error) {
 	if pattern == "" {
 		pattern = DefaultPattern
 	}
-	tmpl := template.Must(template.New("log").Parse(pattern))
-	return &TextFormatter{tmpl: tmpl}
+
+	f := &TextFormatter{Color: ColorEnabled(os.Stdout)}
+	base, err := template.New("log").Funcs(f.funcs(nil)).Parse(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid text pattern: %w", err)
+	}
+
+	f.compiled = compileTemplate(base)
+	f.templates.New = func() any {
+		// every pooled template has its own record slot so 'field' can be bound per execution
+		t := &boundTemplate{}
+		t.tmpl = template.Must(base.Clone()).Funcs(f.funcs(t))
+		return t
+	}
+	return f, nil
 }
 
 // function 'Format' formats the given record as text
@@ -75,27 +111,35 @@ func (f *TextFormatter) Format(r Record) []byte {
 	buf.Reset()
 	defer bufPool.Put(buf)
 
-	var sb bytes.Buffer
-	for i, field := range r.Fields {
-		sb.WriteString(fmt.Sprintf("%s=%v", field.Key, field.Value))
-		if i < len(r.Fields)-1 {
-			sb.WriteByte(' ')
+	if f.compiled != nil {
+		for _, segment := range f.compiled {
+			if segment.key == "" {
+				buf.WriteString(segment.text)
+				continue
+			}
+			buf.WriteString(f.textValue(segment.key, r))
+		}
+	} else {
+		t := f.templates.Get().(*boundTemplate)
+		t.rec = &r
+		err := t.tmpl.Execute(buf, f.textData(r))
+		t.rec = nil
+		f.templates.Put(t)
+		if err != nil {
+			buf.WriteString(fmt.Sprintf("logger: template error: %v", err))
 		}
 	}
 
-	data := map[string]any{
-		"level":     r.Level.String(),
-		"message":   r.Message,
-		"caller":    r.Caller,
-		"fields":    sb.String(),
-		"timestamp": r.Timestamp.Format(time.RFC3339Nano),
-	}
-
-	err := f.tmpl.Execute(buf, data)
-	if err != nil {
-		buf.WriteString(fmt.Sprintf("logger: template error: %v", err))
+	for _, frame := range r.Stack {
+		buf.WriteString("\n    ")
+		buf.WriteString(frame.Function)
+		buf.WriteString("\n        ")
+		buf.WriteString(frame.File)
+		buf.WriteByte(':')
+		buf.WriteString(strconv.Itoa(frame.Line))
 	}
 
 	buf.WriteByte('\n')
-	return buf.Bytes()
+	// the buffer goes back to the pool so the caller gets its own copy
+	return append([]byte(nil), buf.Bytes()...)
 }
@@ -2,10 +2,57 @@ package logger
 
 import (
 	"context"
+	"fmt"
 )
 
 // type 'Handler' represents a logging handler,
-// concrete implementations are 'ConsoleHandler' and 'FileHandler'
+// concrete implementations are 'ConsoleHandler' and 'FileHandler',
+// the returned error is reported by the dispatcher through the internal error handler as a 'HandlerError'
 type Handler interface {
+	Handle(ctx context.Context, r Record) error
+}
+
+// type 'LegacyHandler' represents a logging handler that cannot report failures
+type LegacyHandler interface {
 	Handle(ctx context.Context, r Record)
 }
+
+// function 'AdaptHandler' adapts the given legacy handler to 'Handler',
+// a panic while handling the record is reported as an error
+func AdaptHandler(h LegacyHandler) Handler {
+	return handlerAdapter{h}
+}
+
+// struct 'handlerAdapter' implements 'Handler' interface on top of a 'LegacyHandler'
+type handlerAdapter struct {
+	h LegacyHandler
+}
+
+// function 'Handle' handles the given record with the adapted handler
+func (a handlerAdapter) Handle(ctx context.Context, r Record) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic in handler %T: %v", a.h, p)
+		}
+	}()
+	a.h.Handle(ctx, r)
+	return nil
+}
+
+// struct 'HandlerError' represents a failure of a handler to handle a record,
+// it carries the failing handler and the record that was not delivered
+type HandlerError struct {
+	Handler Handler
+	Record  Record
+	Err     error
+}
+
+// function 'Error' returns the message of the handler error
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("handler %T failed on %s record %q: %v", e.Handler, e.Record.Level, e.Record.Message, e.Err)
+}
+
+// function 'Unwrap' returns the underlying error
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+// struct 'AsyncHandler' implements 'Handler' interface,
+// it gives the wrapped handler its own goroutine so a slow handler does not hold up the others,
+// errors of the background goroutine are returned by the next call to 'Handle'
+type AsyncHandler struct {
+	handler   logger.Handler
+	records   chan asyncEntry
+	errs      pendingErrors
+	mu        sync.RWMutex
+	closed    bool
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// struct 'asyncEntry' represents a queued record together with its context
+type asyncEntry struct {
+	ctx context.Context
+	rec logger.Record
+}
+
+// function 'NewAsyncHandler' creates a new 'AsyncHandler' that queues up to buffer size records
+func NewAsyncHandler(h logger.Handler, bufferSize int) *AsyncHandler {
+	if bufferSize <= 0 {
+		bufferSize = 1000
+	}
+
+	a := &AsyncHandler{
+		handler: h,
+		records: make(chan asyncEntry, bufferSize),
+	}
+
+	a.wg.Add(1)
+	go a.run()
+
+	return a
+}
+
+// function 'Handle' queues the given record, it blocks while the queue is full until the context is done,
+// the record is handled with a context detached from the caller because the caller does not wait for it,
+// records handed in after 'Close' are rejected
+func (h *AsyncHandler) Handle(ctx context.Context, r logger.Record) error {
+	// the read lock keeps 'Close' from closing the queue while a record is being sent
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		return errHandlerClosed
+	}
+
+	select {
+	case h.records <- asyncEntry{ctx: context.WithoutCancel(ctx), rec: r}:
+	case <-ctx.Done():
+		h.errs.add(fmt.Errorf("async queue full: %w", ctx.Err()))
+	}
+	return h.errs.take()
+}
+
+// function 'Close' handles the queued records and stops the background goroutine
+func (h *AsyncHandler) Close() error {
+	h.closeOnce.Do(func() {
+		h.mu.Lock()
+		h.closed = true
+		close(h.records)
+		h.mu.Unlock()
+		h.wg.Wait()
+	})
+	return h.errs.take()
+}
+
+// function 'run' handles the queued records
+func (h *AsyncHandler) run() {
+	defer h.wg.Done()
+	for entry := range h.records {
+		if err := safeHandle(entry.ctx, h.handler, entry.rec); err != nil {
+			h.errs.add(err)
+		}
+	}
+}
+
+// function 'safeHandle' hands a record to a handler and converts a panic into an error,
+// handlers running on goroutines of their own are out of reach of the recovery of the dispatcher
+func safeHandle(ctx context.Context, h logger.Handler, r logger.Record) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("recovered from panic in handler %T: %v", h, p)
+		}
+	}()
+	return h.Handle(ctx, r)
+}
+
+// struct 'pendingErrors' collects errors of background work until they can be returned to a caller
+type pendingErrors struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+// function 'add' records the given error
+func (p *pendingErrors) add(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// only a bounded number of errors is kept, a broken handler fails on every record
+	if len(p.errs) < 10 {
+		p.errs = append(p.errs, err)
+	}
+}
+
+// function 'take' returns the recorded errors joined together and forgets them
+func (p *pendingErrors) take() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	err := errors.Join(p.errs...)
+	p.errs = nil
+	return err
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+// struct 'BufferedHandler' implements 'Handler' interface,
+// it collects records in memory and hands them to the wrapped handler in batches,
+// a batch is flushed when it reaches the batch size or every flush interval,
+// errors of interval flushes are returned by the next call to 'Handle'
+type BufferedHandler struct {
+	handler   logger.Handler
+	size      int
+	flushMu   sync.Mutex
+	mu        sync.Mutex
+	batch     []asyncEntry
+	closed    bool
+	errs      pendingErrors
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// function 'NewBufferedHandler' creates a new 'BufferedHandler' with the given batch size and flush interval
+func NewBufferedHandler(h logger.Handler, size int, flushInterval time.Duration) *BufferedHandler {
+	if size <= 0 {
+		size = 100
+	}
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
+
+	b := &BufferedHandler{
+		handler: h,
+		size:    size,
+		batch:   make([]asyncEntry, 0, size),
+		done:    make(chan struct{}),
+	}
+
+	b.wg.Add(1)
+	go b.run(flushInterval)
+
+	return b
+}
+
+// function 'Handle' adds the given record to the current batch and flushes it when it is full,
+// records handed in after 'Close' are rejected
+func (h *BufferedHandler) Handle(ctx context.Context, r logger.Record) error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return errHandlerClosed
+	}
+	h.batch = append(h.batch, asyncEntry{ctx: context.WithoutCancel(ctx), rec: r})
+	full := len(h.batch) >= h.size
+	h.mu.Unlock()
+
+	if full {
+		h.Flush()
+	}
+	return h.errs.take()
+}
+
+// function 'Flush' hands the current batch to the wrapped handler
+func (h *BufferedHandler) Flush() {
+	// flushes are serialized so batches reach the wrapped handler in order
+	h.flushMu.Lock()
+	defer h.flushMu.Unlock()
+
+	h.mu.Lock()
+	batch := h.batch
+	h.batch = make([]asyncEntry, 0, h.size)
+	h.mu.Unlock()
+
+	for _, entry := range batch {
+		if err := safeHandle(entry.ctx, h.handler, entry.rec); err != nil {
+			h.errs.add(err)
+		}
+	}
+}
+
+// function 'Close' flushes the current batch and stops the interval flushes
+func (h *BufferedHandler) Close() error {
+	h.closeOnce.Do(func() {
+		// records are rejected from now on so the final flush delivers every accepted one
+		h.mu.Lock()
+		h.closed = true
+		h.mu.Unlock()
+		close(h.done)
+		h.wg.Wait()
+		h.Flush()
+	})
+	return h.errs.take()
+}
+
+// function 'run' flushes the current batch every interval
+func (h *BufferedHandler) run(interval time.Duration) {
+	defer h.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			h.Flush()
+		case <-h.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+// struct 'panicHandler' implements 'Handler' interface by panicking on every record
+type panicHandler struct{}
+
+func (panicHandler) Handle(context.Context, logger.Record) error {
+	panic("boom")
+}
+
+// struct 'countingHandler' implements 'Handler' interface by counting records
+type countingHandler struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (h *countingHandler) Handle(context.Context, logger.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.n++
+	return nil
+}
+
+func (h *countingHandler) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.n
+}
+
+func isRecoveredPanic(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "recovered from panic")
+}
+
+func TestFanoutHandlerRecoversPanics(t *testing.T) {
+	ok := &countingHandler{}
+	h := NewFanoutHandler(panicHandler{}, ok)
+	if err := h.Handle(context.Background(), logger.Record{}); !isRecoveredPanic(err) {
+		t.Errorf("got %v, want the panic as an error", err)
+	}
+	if ok.count() != 1 {
+		t.Errorf("the healthy handler got %d records, want 1", ok.count())
+	}
+}
+
+func TestFailoverHandlerRecoversPanics(t *testing.T) {
+	for _, timeout := range []time.Duration{0, time.Second} {
+		secondary := &countingHandler{}
+		h := NewFailoverHandler(panicHandler{}, secondary, timeout)
+		if err := h.Handle(context.Background(), logger.Record{}); err != nil {
+			t.Errorf("timeout %v: got %v, want the secondary to take over", timeout, err)
+		}
+		if secondary.count() != 1 {
+			t.Errorf("timeout %v: the secondary got %d records, want 1", timeout, secondary.count())
+		}
+	}
+}
+
+func TestAsyncHandlerRecoversPanics(t *testing.T) {
+	h := NewAsyncHandler(panicHandler{}, 10)
+	h.Handle(context.Background(), logger.Record{})
+	if err := h.Close(); !isRecoveredPanic(err) {
+		t.Errorf("got %v, want the panic as an error", err)
+	}
+}
+
+func TestBufferedHandlerRecoversPanics(t *testing.T) {
+	h := NewBufferedHandler(panicHandler{}, 10, time.Hour)
+	h.Handle(context.Background(), logger.Record{})
+	if err := h.Close(); !isRecoveredPanic(err) {
+		t.Errorf("got %v, want the panic as an error", err)
+	}
+}
+
+func TestAsyncHandlerRejectsRecordsAfterClose(t *testing.T) {
+	ok := &countingHandler{}
+	h := NewAsyncHandler(ok, 10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if err := h.Handle(context.Background(), logger.Record{}); errors.Is(err, errHandlerClosed) {
+					return
+				}
+			}
+		}()
+	}
+	h.Close()
+	wg.Wait()
+
+	if err := h.Handle(context.Background(), logger.Record{}); !errors.Is(err, errHandlerClosed) {
+		t.Errorf("got %v, want %v", err, errHandlerClosed)
+	}
+}
+
+func TestBufferedHandlerDeliversAcceptedRecordsAndRejectsAfterClose(t *testing.T) {
+	ok := &countingHandler{}
+	h := NewBufferedHandler(ok, 7, time.Hour)
+
+	var accepted atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				err := h.Handle(context.Background(), logger.Record{})
+				if errors.Is(err, errHandlerClosed) {
+					return
+				}
+				accepted.Add(1)
+			}
+		}()
+	}
+	h.Close()
+	wg.Wait()
+
+	if err := h.Handle(context.Background(), logger.Record{}); !errors.Is(err, errHandlerClosed) {
+		t.Errorf("got %v, want %v", err, errHandlerClosed)
+	}
+	if got := ok.count(); int64(got) != accepted.Load() {
+		t.Errorf("delivered %d of %d accepted records", got, accepted.Load())
+	}
+}
@@ -3,17 +3,48 @@ package handlers
 import (
 	"context"
 	"os"
+	"sync"
 
 	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
 )
 
-// struct 'ConsoleHandler' implements 'Handler' interface
+// struct 'ConsoleHandler' implements 'Handler' interface,
+// when no formatter is set, records of the development environment are formatted
+// with 'DevFormatter' and all other records with 'JSONFormatter',
+// the environment is taken from the "environment" field set by 'WithEnvironment'
 type ConsoleHandler struct {
 	Formatter logger.Formatter
+	once      sync.Once
+	dev       logger.Formatter
+	prod      logger.Formatter
 }
 
+// variable 'devEnvironments' are the environment names that select the development formatter
+var devEnvironments = map[string]bool{"dev": true, "development": true, "local": true}
+
 // function 'Handle' handles the given record by formatting it and writing it to the console
-func (h *ConsoleHandler) Handle(ctx context.Context, r logger.Record) {
-	output := h.Formatter.Format(r)
-	os.Stdout.Write(output)
+func (h *ConsoleHandler) Handle(ctx context.Context, r logger.Record) error {
+	output := h.formatter(r).Format(r)
+	_, err := os.Stdout.Write(output)
+	return err
+}
+
+// function 'formatter' returns the configured formatter or picks one based on the record environment
+func (h *ConsoleHandler) formatter(r logger.Record) logger.Formatter {
+	if h.Formatter != nil {
+		return h.Formatter
+	}
+
+	h.once.Do(func() {
+		h.dev = logger.NewDevFormatter()
+		h.prod = logger.NewJSONFormatter()
+	})
+	for _, field := range r.Fields {
+		if field.Key == "environment" {
+			if env, ok := field.Value.(string); ok && devEnvironments[env] {
+				return h.dev
+			}
+		}
+	}
+	return h.prod
 }
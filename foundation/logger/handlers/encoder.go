@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+// type 'BodyEncoder' represents an encoder that turns a batch of records into an HTTP request body,
+// concrete implementations are 'LokiEncoder', 'ElasticsearchEncoder' and 'NDJSONEncoder'
+type BodyEncoder interface {
+	// function 'Encode' encodes the given batch of records
+	Encode(records []logger.Record) ([]byte, error)
+	// function 'ContentType' returns the media type of the encoded body
+	ContentType() string
+}
+
+// struct 'LokiEncoder' implements 'BodyEncoder' interface for the Loki push API,
+// records are grouped into streams by the labels taken from 'LabelKeys' and 'StaticLabels',
+// the special label key "level" resolves to the record level
+type LokiEncoder struct {
+	LabelKeys    []string
+	StaticLabels map[string]string
+	Formatter    logger.Formatter
+}
+
+// variable 'defaultLokiLabelKeys' are the label keys used in case of missing keys
+var defaultLokiLabelKeys = []string{"service", "environment", "level"}
+
+// struct 'lokiPush' represents the body of a Loki push request
+type lokiPush struct {
+	Streams []*lokiStream `json:"streams"`
+}
+
+// struct 'lokiStream' represents a single labeled stream of a Loki push request
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// function 'Encode' encodes the given batch of records as a Loki push request
+func (e *LokiEncoder) Encode(records []logger.Record) ([]byte, error) {
+	formatter := e.Formatter
+	if formatter == nil {
+		formatter = logger.NewJSONFormatter()
+	}
+	keys := e.LabelKeys
+	if keys == nil {
+		keys = defaultLokiLabelKeys
+	}
+
+	push := lokiPush{}
+	streams := make(map[string]*lokiStream)
+	for _, r := range records {
+		labels := e.labels(keys, r)
+		id := labelSetId(labels)
+		stream, ok := streams[id]
+		if !ok {
+			stream = &lokiStream{Stream: labels}
+			streams[id] = stream
+			push.Streams = append(push.Streams, stream)
+		}
+		line := strings.TrimSuffix(string(formatter.Format(r)), "\n")
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(r.Timestamp.UnixNano(), 10), line})
+	}
+
+	return json.Marshal(push)
+}
+
+// function 'ContentType' returns the media type of the Loki push body
+func (e *LokiEncoder) ContentType() string {
+	return "application/json"
+}
+
+// function 'labels' returns the stream labels of the given record
+func (e *LokiEncoder) labels(keys []string, r logger.Record) map[string]string {
+	labels := make(map[string]string, len(keys)+len(e.StaticLabels))
+	for k, v := range e.StaticLabels {
+		labels[k] = v
+	}
+	for _, key := range keys {
+		if key == "level" {
+			labels[key] = r.Level.String()
+			continue
+		}
+		for _, field := range r.Fields {
+			if field.Key == key {
+				labels[key] = fieldValue(field.Value)
+			}
+		}
+	}
+	return labels
+}
+
+// function 'labelSetId' returns a stable identifier for a set of labels
+func labelSetId(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(labels[k])
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
+
+// struct 'ElasticsearchEncoder' implements 'BodyEncoder' interface for the Elasticsearch _bulk API,
+// every record is indexed into 'Index' as a separate document
+type ElasticsearchEncoder struct {
+	Index     string
+	Formatter logger.Formatter
+}
+
+// function 'Encode' encodes the given batch of records as a _bulk request body
+func (e *ElasticsearchEncoder) Encode(records []logger.Record) ([]byte, error) {
+	formatter := e.Formatter
+	if formatter == nil {
+		formatter = logger.NewJSONFormatter()
+	}
+	index := e.Index
+	if index == "" {
+		index = "logs"
+	}
+
+	action, err := json.Marshal(map[string]any{"index": map[string]string{"_index": index}})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, r := range records {
+		buf.Write(action)
+		buf.WriteByte('\n')
+		writeLine(&buf, formatter.Format(r))
+	}
+	return buf.Bytes(), nil
+}
+
+// function 'ContentType' returns the media type of the _bulk body
+func (e *ElasticsearchEncoder) ContentType() string {
+	return "application/x-ndjson"
+}
+
+// struct 'NDJSONEncoder' implements 'BodyEncoder' interface,
+// it writes one formatted record per line
+type NDJSONEncoder struct {
+	Formatter logger.Formatter
+}
+
+// function 'Encode' encodes the given batch of records as newline delimited JSON
+func (e *NDJSONEncoder) Encode(records []logger.Record) ([]byte, error) {
+	formatter := e.Formatter
+	if formatter == nil {
+		formatter = logger.NewJSONFormatter()
+	}
+
+	var buf bytes.Buffer
+	for _, r := range records {
+		writeLine(&buf, formatter.Format(r))
+	}
+	return buf.Bytes(), nil
+}
+
+// function 'ContentType' returns the media type of newline delimited JSON
+func (e *NDJSONEncoder) ContentType() string {
+	return "application/x-ndjson"
+}
+
+// function 'writeLine' writes the given formatted record and makes sure it ends with a newline
+func writeLine(buf *bytes.Buffer, line []byte) {
+	buf.Write(line)
+	if len(line) == 0 || line[len(line)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+}
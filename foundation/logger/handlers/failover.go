@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+// struct 'FailoverHandler' implements 'Handler' interface,
+// it writes every record to 'Primary' and falls back to 'Secondary'
+// when the primary returns an error or does not finish within 'Timeout',
+// a zero timeout calls the primary directly and relies on it to honor the context,
+// a panic of the primary counts as a failure
+type FailoverHandler struct {
+	Primary   logger.Handler
+	Secondary logger.Handler
+	Timeout   time.Duration
+}
+
+// function 'NewFailoverHandler' creates a new 'FailoverHandler' with the given handlers and timeout
+func NewFailoverHandler(primary, secondary logger.Handler, timeout time.Duration) *FailoverHandler {
+	return &FailoverHandler{
+		Primary:   primary,
+		Secondary: secondary,
+		Timeout:   timeout,
+	}
+}
+
+// function 'Handle' writes the given record to the primary handler and on failure to the secondary,
+// it returns an error only when both handlers failed
+func (h *FailoverHandler) Handle(ctx context.Context, r logger.Record) error {
+	primaryErr := h.primary(ctx, r)
+	if primaryErr == nil {
+		return nil
+	}
+
+	if err := h.Secondary.Handle(ctx, r); err != nil {
+		return errors.Join(fmt.Errorf("primary: %w", primaryErr), fmt.Errorf("secondary: %w", err))
+	}
+	return nil
+}
+
+// function 'primary' writes the given record to the primary handler within the timeout,
+// a primary that times out keeps running in the background and its result is discarded,
+// without timeout the primary is called directly so a hung primary does not leak a goroutine per record
+func (h *FailoverHandler) primary(ctx context.Context, r logger.Record) error {
+	if h.Timeout <= 0 {
+		return safeHandle(ctx, h.Primary, r)
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- safeHandle(ctx, h.Primary, r)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
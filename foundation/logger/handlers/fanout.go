@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+// struct 'FanoutHandler' implements 'Handler' interface,
+// it sends every record to all of its handlers concurrently
+type FanoutHandler struct {
+	Handlers []logger.Handler
+}
+
+// function 'NewFanoutHandler' creates a new 'FanoutHandler' that sends records to the given handlers
+func NewFanoutHandler(handlers ...logger.Handler) *FanoutHandler {
+	return &FanoutHandler{Handlers: handlers}
+}
+
+// function 'Handle' sends the given record to all handlers and waits for them,
+// it returns the errors of all failed handlers joined together
+func (h *FanoutHandler) Handle(ctx context.Context, r logger.Record) error {
+	if len(h.Handlers) == 1 {
+		return h.Handlers[0].Handle(ctx, r)
+	}
+
+	errs := make([]error, len(h.Handlers))
+	var wg sync.WaitGroup
+	for i, handler := range h.Handlers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = safeHandle(ctx, handler, r)
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
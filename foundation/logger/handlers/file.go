@@ -2,27 +2,72 @@ package handlers
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"sync"
 
 	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
 )
 
-// struct 'FileHandler' implements 'Handler' interface
+// struct 'FileHandler' implements 'Handler' interface,
+// 'Path' is the path 'File' was opened from, it is only needed by 'Reopen'
 type FileHandler struct {
 	File      *os.File
+	Path      string
 	Formatter logger.Formatter
 	mu        sync.Mutex
 }
 
+// function 'OpenFileHandler' creates a new 'FileHandler' appending to the file at the given path,
+// the file is created when it does not exist
+func OpenFileHandler(path string, formatter logger.Formatter) (*FileHandler, error) {
+	file, err := openLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &FileHandler{File: file, Path: path, Formatter: formatter}, nil
+}
+
 // function 'Handle' handles the given record by formatting it and writing it to the file
-func (h *FileHandler) Handle(ctx context.Context, r logger.Record) {
+func (h *FileHandler) Handle(ctx context.Context, r logger.Record) error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	output := h.Formatter.Format(r)
 	_, err := h.File.Write(output)
+	return err
+}
+
+// function 'Reopen' reopens the file at 'Path' when the open file was moved or removed,
+// e.g. by logrotate, so records are written to the new file instead of the rotated one
+func (h *FileHandler) Reopen() error {
+	if h.Path == "" {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if current, err := h.File.Stat(); err == nil {
+		if onDisk, err := os.Stat(h.Path); err == nil && os.SameFile(current, onDisk) {
+			return nil
+		}
+	}
+
+	file, err := openLogFile(h.Path)
 	if err != nil {
-		fmt.Printf("logger: file write error: %v\n", err)
+		return err
 	}
+	old := h.File
+	h.File = file
+	return old.Close()
+}
+
+// function 'Close' closes the file
+func (h *FileHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.File.Close()
+}
+
+// function 'openLogFile' opens a file for appending, creating it when it does not exist
+func openLogFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
 }
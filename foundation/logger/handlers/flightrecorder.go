@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+// constant 'defaultMaxTraces' is the number of traces kept by a per-trace flight recorder in case of missing limit
+const defaultMaxTraces = 1000
+
+// struct 'FlightRecorder' implements 'Handler' interface and 'http.Handler' interface,
+// it keeps the last records of all levels in memory and writes only audit records and records of 'Info' and above
+// to the output handler, when an 'Error' record arrives the buffered records of its trace are removed from
+// the buffer and those not written to the output handler are dumped to the target handler,
+// records without a trace id, including those carrying the placeholder for a missing one, share one buffer,
+// the logger must be created with the 'Debug' level so debug records reach the recorder,
+// e.g. 'logger.NewLogger(logger.WithLevel(logger.Debug), logger.WithHandler(recorder))'
+type FlightRecorder struct {
+	output    logger.Handler
+	target    logger.Handler
+	size      int
+	perTrace  bool
+	maxTraces int
+	mu        sync.Mutex
+	global    *ring
+	traces    map[string]*list.Element
+	order     *list.List
+}
+
+// struct 'traceRing' represents the buffered records of a trace in the eviction order of the recorder
+type traceRing struct {
+	traceId string
+	ring    *ring
+}
+
+// struct 'ring' represents a fixed-size buffer keeping the most recent records
+type ring struct {
+	records []logger.Record
+	next    int
+	full    bool
+}
+
+// function 'NewFlightRecorder' creates a new 'FlightRecorder' keeping the last size records,
+// with perTrace set it keeps the last size records of every trace id for up to 1000 traces,
+// evicting the least recently active trace first, records without trace id share one buffer,
+// the target is required while a nil output only keeps records in memory until they are dumped
+func NewFlightRecorder(output, target logger.Handler, size int, perTrace bool) (*FlightRecorder, error) {
+	if target == nil {
+		return nil, errors.New("flight recorder: target handler is required")
+	}
+	if size <= 0 {
+		size = 100
+	}
+	return &FlightRecorder{
+		output:    output,
+		target:    target,
+		size:      size,
+		perTrace:  perTrace,
+		maxTraces: defaultMaxTraces,
+		global:    newRing(size),
+		traces:    map[string]*list.Element{},
+		order:     list.New(),
+	}, nil
+}
+
+// function 'Handle' buffers the given record, writes it to the output handler when it is 'Info' or above or an audit record
+// and dumps the buffered records of its trace when it is an 'Error'
+func (h *FlightRecorder) Handle(ctx context.Context, r logger.Record) error {
+	var dump []logger.Record
+	traceId := r.KnownTraceId()
+	h.mu.Lock()
+	buffer := h.buffer(traceId)
+	buffer.add(r)
+	if r.Level == logger.Error {
+		dump = h.take(buffer, traceId)
+	}
+	h.mu.Unlock()
+
+	var errs []error
+	if h.writes(r) {
+		if err := h.output.Handle(ctx, r); err != nil {
+			errs = append(errs, fmt.Errorf("output: %w", err))
+		}
+	}
+	for _, rec := range dump {
+		if h.writes(rec) {
+			// the record already reached the output handler
+			continue
+		}
+		if err := h.target.Handle(ctx, rec); err != nil {
+			errs = append(errs, fmt.Errorf("dump: %w", err))
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// function 'writes' reports whether the given record is written to the output handler
+func (h *FlightRecorder) writes(r logger.Record) bool {
+	return h.output != nil && (r.Level >= logger.Info || r.Level == logger.Audit)
+}
+
+// function 'Records' returns a copy of the buffered records, oldest first,
+// an empty trace id returns the records of all traces
+func (h *FlightRecorder) Records(traceId string) []logger.Record {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.perTrace {
+		if traceId != "" {
+			if e, ok := h.traces[traceId]; ok {
+				return e.Value.(*traceRing).ring.snapshot(nil)
+			}
+			return nil
+		}
+		records := h.global.snapshot(nil)
+		for e := h.order.Front(); e != nil; e = e.Next() {
+			records = e.Value.(*traceRing).ring.snapshot(records)
+		}
+		return records
+	}
+
+	records := h.global.snapshot(nil)
+	if traceId == "" {
+		return records
+	}
+	filtered := records[:0]
+	for _, r := range records {
+		if r.KnownTraceId() == traceId {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// function 'ServeHTTP' writes the buffered records as newline delimited JSON for live inspection,
+// the "trace_id" query parameter restricts the records to a single trace, the buffer is left untouched
+func (h *FlightRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	records := h.Records(req.URL.Query().Get("trace_id"))
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-store")
+	if req.Method == http.MethodHead {
+		return
+	}
+	formatter := logger.NewJSONFormatter()
+	for _, r := range records {
+		if _, err := w.Write(formatter.Format(r)); err != nil {
+			return
+		}
+	}
+}
+
+// function 'buffer' returns the buffer of the given trace id, it is called with the lock held
+func (h *FlightRecorder) buffer(traceId string) *ring {
+	if !h.perTrace || traceId == "" {
+		return h.global
+	}
+
+	if e, ok := h.traces[traceId]; ok {
+		h.order.MoveToBack(e)
+		return e.Value.(*traceRing).ring
+	}
+	if h.order.Len() >= h.maxTraces {
+		oldest := h.order.Front()
+		h.order.Remove(oldest)
+		delete(h.traces, oldest.Value.(*traceRing).traceId)
+	}
+	t := &traceRing{traceId: traceId, ring: newRing(h.size)}
+	h.traces[traceId] = h.order.PushBack(t)
+	return t.ring
+}
+
+// function 'take' removes and returns the buffered records of a trace, it is called with the lock held,
+// the shared buffer keeps the records of other traces
+func (h *FlightRecorder) take(buffer *ring, traceId string) []logger.Record {
+	if h.perTrace && traceId != "" {
+		records := buffer.snapshot(nil)
+		h.order.Remove(h.traces[traceId])
+		delete(h.traces, traceId)
+		return records
+	}
+
+	records := buffer.snapshot(nil)
+	if traceId == "" {
+		buffer.reset()
+		return records
+	}
+	var taken, kept []logger.Record
+	for _, r := range records {
+		if r.KnownTraceId() == traceId {
+			taken = append(taken, r)
+		} else {
+			kept = append(kept, r)
+		}
+	}
+	buffer.reset()
+	for _, r := range kept {
+		buffer.add(r)
+	}
+	return taken
+}
+
+// function 'newRing' creates a new ring keeping the given number of records
+func newRing(size int) *ring {
+	return &ring{records: make([]logger.Record, size)}
+}
+
+// function 'add' adds a record, overwriting the oldest one when the ring is full
+func (b *ring) add(r logger.Record) {
+	b.records[b.next] = r
+	b.next = (b.next + 1) % len(b.records)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// function 'snapshot' appends the records to dst, oldest first
+func (b *ring) snapshot(dst []logger.Record) []logger.Record {
+	if b.full {
+		dst = append(dst, b.records[b.next:]...)
+	}
+	return append(dst, b.records[:b.next]...)
+}
+
+// function 'reset' removes all records
+func (b *ring) reset() {
+	clear(b.records)
+	b.next = 0
+	b.full = false
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger/loggertest"
+)
+
+func TestFlightRecorderRequiresTarget(t *testing.T) {
+	if _, err := NewFlightRecorder(loggertest.NewRecorder(), nil, 10, false); err == nil {
+		t.Error("expected an error for a nil target")
+	}
+}
+
+func TestFlightRecorderDumpsOnlyRecordsNotWritten(t *testing.T) {
+	for _, perTrace := range []bool{false, true} {
+		output := loggertest.NewRecorder()
+		recorder, err := NewFlightRecorder(output, output, 10, perTrace)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx := context.Background()
+		for _, r := range []logger.Record{
+			{Level: logger.Debug, Message: "query", TraceId: "t1"},
+			{Level: logger.Info, Message: "request", TraceId: "t1"},
+			{Level: logger.Debug, Message: "other trace", TraceId: "t2"},
+			{Level: logger.Error, Message: "failed", TraceId: "t1"},
+		} {
+			if err := recorder.Handle(ctx, r); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var messages []string
+		for _, r := range output.Records() {
+			messages = append(messages, r.Message)
+		}
+		want := []string{"request", "failed", "query"}
+		if len(messages) != len(want) {
+			t.Fatalf("perTrace=%v: got %v, want %v", perTrace, messages, want)
+		}
+		for i := range want {
+			if messages[i] != want[i] {
+				t.Errorf("perTrace=%v: got %v, want %v", perTrace, messages, want)
+				break
+			}
+		}
+		if got := recorder.Records("t2"); len(got) != 1 {
+			t.Errorf("perTrace=%v: records of other traces were dumped: %v", perTrace, got)
+		}
+	}
+}
+
+func TestFlightRecorderTreatsPlaceholderAsNoTrace(t *testing.T) {
+	target := loggertest.NewRecorder()
+	recorder, err := NewFlightRecorder(nil, target, 10, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// records logged without a trace id carry the placeholder of the logger
+	l := logger.NewLogger(logger.WithLevel(logger.Debug), logger.WithSynchronous(), logger.WithHandler(recorder))
+	defer l.Close()
+	l.Debug("first")
+	recorder.Handle(context.Background(), logger.Record{Level: logger.Debug, Message: "second"})
+
+	if got := recorder.Records(""); len(got) != 2 {
+		t.Fatalf("got %d records", len(got))
+	}
+	l.Error("failed")
+	loggertest.CountByLevel(t, target, logger.Debug, 2)
+	loggertest.CountByLevel(t, target, logger.Error, 1)
+}
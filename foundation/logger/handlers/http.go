@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+// constant 'maxRetryDelay' caps the delay between attempts, including the one requested through Retry-After
+const maxRetryDelay = 30 * time.Second
+
+// variable 'errHandlerClosed' is returned when a record is handed to a closed handler
+var errHandlerClosed = errors.New("handler is closed")
+
+// struct 'HTTPHandler' implements 'Handler' interface,
+// it batches records and posts them to a log ingestion endpoint using 'Encoder',
+// a batch is sent when it reaches 'MaxBatchSize' records or every 'FlushInterval',
+// requests failing with a network error, 429 or 5xx are retried with jittered exponential backoff
+// up to 'MaxRetries' times, a negative value disables retries,
+// 'Authorize' is called on every request to attach authentication headers,
+// 'ErrorHandler' receives the failures of batches sent in the background, e.g. the function given to
+// 'logger.WithInternalErrorHandler', without it they are returned by 'Close'
+type HTTPHandler struct {
+	URL           string
+	Encoder       BodyEncoder
+	Client        *http.Client
+	Headers       map[string]string
+	Authorize     func(req *http.Request) error
+	Gzip          bool
+	MaxBatchSize  int
+	FlushInterval time.Duration
+	MaxRetries    int
+	RetryBackoff  time.Duration
+	ErrorHandler  func(error)
+	once          sync.Once
+	records       chan logger.Record
+	flush         chan chan struct{}
+	done          chan struct{}
+	mu            sync.RWMutex
+	closed        bool
+	errs          pendingErrors
+	closeOnce     sync.Once
+	wg            sync.WaitGroup
+}
+
+// function 'Handle' handles the given record by queueing it for the next batch,
+// it blocks while the queue is full until the context is done,
+// records handed in after 'Close' are rejected, failures of batches are reported to 'ErrorHandler'
+func (h *HTTPHandler) Handle(ctx context.Context, r logger.Record) error {
+	h.once.Do(h.start)
+
+	// the read lock keeps 'Close' from draining the queue while a record is being queued
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		return errHandlerClosed
+	}
+
+	select {
+	case h.records <- r:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("http queue full: %w", ctx.Err())
+	}
+}
+
+// function 'Flush' sends the queued records and waits until the batch is delivered or given up
+func (h *HTTPHandler) Flush() {
+	h.once.Do(h.start)
+
+	ack := make(chan struct{})
+	select {
+	case h.flush <- ack:
+		<-ack
+	case <-h.done:
+	}
+}
+
+// function 'Close' sends the queued records and stops the background sender,
+// batches are attempted once more but not retried, a batch waiting for a retry is given up,
+// it returns the failures of batches not reported to 'ErrorHandler'
+func (h *HTTPHandler) Close() error {
+	h.once.Do(h.start)
+	h.closeOnce.Do(func() {
+		h.mu.Lock()
+		h.closed = true
+		close(h.done)
+		h.mu.Unlock()
+		h.wg.Wait()
+	})
+	return h.errs.take()
+}
+
+// function 'report' reports the failure of a batch to 'ErrorHandler' or keeps it for 'Close'
+func (h *HTTPHandler) report(err error) {
+	if h.ErrorHandler != nil {
+		h.ErrorHandler(err)
+		return
+	}
+	h.errs.add(err)
+}
+
+// function 'BearerAuth' returns an 'Authorize' hook that sets a bearer token
+func BearerAuth(token string) func(req *http.Request) error {
+	return func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+}
+
+// function 'BasicAuth' returns an 'Authorize' hook that sets basic authentication credentials
+func BasicAuth(username, password string) func(req *http.Request) error {
+	return func(req *http.Request) error {
+		req.SetBasicAuth(username, password)
+		return nil
+	}
+}
+
+// function 'start' applies defaults and starts the background sender
+func (h *HTTPHandler) start() {
+	if h.Encoder == nil {
+		h.Encoder = &NDJSONEncoder{}
+	}
+	if h.Client == nil {
+		h.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if h.MaxBatchSize <= 0 {
+		h.MaxBatchSize = 100
+	}
+	if h.FlushInterval <= 0 {
+		h.FlushInterval = time.Second
+	}
+	if h.MaxRetries < 0 {
+		h.MaxRetries = 0
+	} else if h.MaxRetries == 0 {
+		h.MaxRetries = 3
+	}
+	if h.RetryBackoff <= 0 {
+		h.RetryBackoff = 500 * time.Millisecond
+	}
+
+	h.records = make(chan logger.Record, h.MaxBatchSize)
+	h.flush = make(chan chan struct{})
+	h.done = make(chan struct{})
+
+	h.wg.Add(1)
+	go h.run()
+}
+
+// function 'run' collects records into batches and sends them
+func (h *HTTPHandler) run() {
+	defer h.wg.Done()
+
+	ticker := time.NewTicker(h.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]logger.Record, 0, h.MaxBatchSize)
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := h.send(batch); err != nil {
+			h.report(err)
+		}
+		batch = make([]logger.Record, 0, h.MaxBatchSize)
+	}
+	drain := func() {
+		for {
+			select {
+			case r := <-h.records:
+				batch = append(batch, r)
+				if len(batch) >= h.MaxBatchSize {
+					send()
+				}
+			default:
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case r := <-h.records:
+			batch = append(batch, r)
+			if len(batch) >= h.MaxBatchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case ack := <-h.flush:
+			drain()
+			send()
+			close(ack)
+		case <-h.done:
+			drain()
+			send()
+			return
+		}
+	}
+}
+
+// function 'send' encodes the given batch and posts it, retrying on transient failures,
+// retries stop when the handler is closed so 'Close' is not held up by a backoff
+func (h *HTTPHandler) send(batch []logger.Record) error {
+	body, err := h.Encoder.Encode(batch)
+	if err != nil {
+		return fmt.Errorf("encode batch of %d records: %w", len(batch), err)
+	}
+	if h.Gzip {
+		if body, err = gzipBody(body); err != nil {
+			return fmt.Errorf("compress batch of %d records: %w", len(batch), err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := h.post(body)
+		if err == nil {
+			return nil
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) || attempt >= h.MaxRetries {
+			return fmt.Errorf("post batch of %d records after %d attempts: %w", len(batch), attempt+1, err)
+		}
+		timer := time.NewTimer(h.backoff(attempt, retryAfter))
+		select {
+		case <-timer.C:
+		case <-h.done:
+			timer.Stop()
+			return fmt.Errorf("post batch of %d records after %d attempts: %w: %w", len(batch), attempt+1, errHandlerClosed, err)
+		}
+	}
+}
+
+// struct 'permanentError' represents a failure that must not be retried
+type permanentError struct {
+	err error
+}
+
+// function 'Error' returns the message of the wrapped error
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// function 'Unwrap' returns the wrapped error
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// function 'post' posts the given body once,
+// it returns the delay requested by the server through the Retry-After header
+func (h *HTTPHandler) post(body []byte) (time.Duration, error) {
+	req, err := http.NewRequest(http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, &permanentError{err}
+	}
+	req.Header.Set("Content-Type", h.Encoder.ContentType())
+	if h.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range h.Headers {
+		req.Header.Set(k, v)
+	}
+	if h.Authorize != nil {
+		if err := h.Authorize(req); err != nil {
+			return 0, &permanentError{fmt.Errorf("authorize: %w", err)}
+		}
+	}
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	switch {
+	case resp.StatusCode < 300:
+		return 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return retryAfter(resp.Header.Get("Retry-After")), fmt.Errorf("status %d: %s", resp.StatusCode, msg)
+	default:
+		return 0, &permanentError{fmt.Errorf("status %d: %s", resp.StatusCode, msg)}
+	}
+}
+
+// function 'backoff' returns the delay before the next attempt,
+// it picks a random delay in the upper half of an exponentially growing window unless the server asked for a delay,
+// the delay never exceeds 'maxRetryDelay'
+func (h *HTTPHandler) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return min(retryAfter, maxRetryDelay)
+	}
+	window := h.RetryBackoff << attempt
+	if window <= 0 || window > maxRetryDelay {
+		window = maxRetryDelay
+	}
+	return window/2 + rand.N(window/2+1)
+}
+
+// function 'retryAfter' parses the Retry-After header given in seconds
+func retryAfter(header string) time.Duration {
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// function 'gzipBody' compresses the given body
+func gzipBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+// struct 'ingest' records the bodies posted to a fake ingestion endpoint
+type ingest struct {
+	mu      sync.Mutex
+	batches [][]string
+	headers []http.Header
+}
+
+func (s *ingest) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		var body io.Reader = req.Body
+		if req.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(req.Body)
+			if err != nil {
+				t.Errorf("invalid gzip body: %v", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = zr
+		}
+		var lines []string
+		scanner := bufio.NewScanner(body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		s.mu.Lock()
+		s.batches = append(s.batches, lines)
+		s.headers = append(s.headers, req.Header.Clone())
+		s.mu.Unlock()
+	}
+}
+
+func (s *ingest) sizes() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sizes := make([]int, len(s.batches))
+	for i, b := range s.batches {
+		sizes[i] = len(b)
+	}
+	return sizes
+}
+
+func TestHTTPHandlerBatching(t *testing.T) {
+	s := &ingest{}
+	srv := httptest.NewServer(s.handler(t))
+	defer srv.Close()
+
+	h := &HTTPHandler{URL: srv.URL, MaxBatchSize: 3, FlushInterval: time.Hour, Headers: map[string]string{"X-Tenant": "t1"}, Authorize: BearerAuth("secret")}
+	for i := 0; i < 7; i++ {
+		if err := h.Handle(context.Background(), logger.Record{Level: logger.Info, Message: "m", Fields: []logger.Field{logger.Int("i", i)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sizes := s.sizes()
+	if len(sizes) != 3 || sizes[0] != 3 || sizes[1] != 3 || sizes[2] != 1 {
+		t.Fatalf("got batch sizes %v, want [3 3 1]", sizes)
+	}
+	header := s.headers[0]
+	if got := header.Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("got content type %q", got)
+	}
+	if got := header.Get("X-Tenant"); got != "t1" {
+		t.Errorf("got tenant header %q", got)
+	}
+	if got := header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("got authorization header %q", got)
+	}
+}
+
+func TestHTTPHandlerGzip(t *testing.T) {
+	s := &ingest{}
+	srv := httptest.NewServer(s.handler(t))
+	defer srv.Close()
+
+	h := &HTTPHandler{URL: srv.URL, Gzip: true, FlushInterval: time.Hour}
+	h.Handle(context.Background(), logger.Record{Level: logger.Info, Message: "compressed"})
+	h.Flush()
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.batches) != 1 || len(s.batches[0]) != 1 || !bytes.Contains([]byte(s.batches[0][0]), []byte(`"message":"compressed"`)) {
+		t.Fatalf("unexpected batches %v", s.batches)
+	}
+	if got := s.headers[0].Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("got content encoding %q", got)
+	}
+}
+
+func TestHTTPHandlerRetry(t *testing.T) {
+	var attempts atomic.Int32
+	s := &ingest{}
+	inner := s.handler(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if attempts.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		inner(w, req)
+	}))
+	defer srv.Close()
+
+	h := &HTTPHandler{URL: srv.URL, FlushInterval: time.Hour, RetryBackoff: time.Millisecond}
+	h.Handle(context.Background(), logger.Record{Level: logger.Info, Message: "retried"})
+	h.Flush()
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("got %d attempts, want 3", got)
+	}
+	if sizes := s.sizes(); len(sizes) != 1 {
+		t.Errorf("got %d delivered batches, want 1", len(sizes))
+	}
+}
+
+func TestHTTPHandlerPermanentFailure(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	h := &HTTPHandler{URL: srv.URL, FlushInterval: time.Hour, RetryBackoff: time.Millisecond}
+	h.Handle(context.Background(), logger.Record{Level: logger.Info, Message: "rejected"})
+	if err := h.Close(); err == nil {
+		t.Fatal("expected the rejected batch to be reported")
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("got %d attempts, want 1", got)
+	}
+}
+
+func TestHTTPHandlerReportsBatchFailuresToErrorHandler(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	reported := make(chan error, 10)
+	h := &HTTPHandler{URL: srv.URL, FlushInterval: time.Hour, ErrorHandler: func(err error) { reported <- err }}
+	h.Handle(context.Background(), logger.Record{Level: logger.Info, Message: "rejected"})
+	h.Flush()
+	if err := h.Handle(context.Background(), logger.Record{Level: logger.Info, Message: "unrelated"}); err != nil {
+		t.Errorf("the failure of an earlier batch was returned for a later record: %v", err)
+	}
+	if err := h.Close(); err != nil {
+		t.Errorf("got %v from Close, want the failures reported to the error handler", err)
+	}
+	if len(reported) != 2 {
+		t.Errorf("got %d reported failures, want 2", len(reported))
+	}
+}
+
+func TestHTTPHandlerDeliversOrRejectsDuringClose(t *testing.T) {
+	s := &ingest{}
+	srv := httptest.NewServer(s.handler(t))
+	defer srv.Close()
+
+	h := &HTTPHandler{URL: srv.URL, MaxBatchSize: 5, FlushInterval: time.Hour}
+	var accepted atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				err := h.Handle(context.Background(), logger.Record{Level: logger.Info, Message: "m"})
+				if errors.Is(err, errHandlerClosed) {
+					return
+				}
+				accepted.Add(1)
+			}
+		}()
+	}
+	time.Sleep(time.Millisecond)
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	delivered := 0
+	for _, n := range s.sizes() {
+		delivered += n
+	}
+	if int64(delivered) != accepted.Load() {
+		t.Errorf("delivered %d of %d accepted records", delivered, accepted.Load())
+	}
+}
+
+func TestHTTPHandlerCloseInterruptsRetryAfter(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		attempts.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	h := &HTTPHandler{URL: srv.URL, FlushInterval: time.Hour}
+	h.Handle(context.Background(), logger.Record{Level: logger.Info, Message: "throttled"})
+	go h.Flush()
+	for attempts.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	start := time.Now()
+	err := h.Close()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("close took %v", elapsed)
+	}
+	if !errors.Is(err, errHandlerClosed) {
+		t.Errorf("got %v, want the batch to be given up on close", err)
+	}
+}
+
+func TestHTTPHandlerBackoffIsCapped(t *testing.T) {
+	h := &HTTPHandler{RetryBackoff: time.Second}
+	if got := h.backoff(0, retryAfter("3600")); got != maxRetryDelay {
+		t.Errorf("got retry-after delay %v, want %v", got, maxRetryDelay)
+	}
+	if got := h.backoff(40, 0); got > maxRetryDelay || got < maxRetryDelay/2 {
+		t.Errorf("got backoff %v, want at most %v", got, maxRetryDelay)
+	}
+}
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+// constant 'defaultOTLPScope' is the instrumentation scope name used in case of missing name
+const defaultOTLPScope = "github.com/eskandaridanial/go-starter-kit/foundation/logger"
+
+// variable 'otlpResourceKeys' maps the well-known logger fields to OpenTelemetry resource attributes,
+// fields found in this table are moved from the log record attributes to the resource
+var otlpResourceKeys = map[string]string{
+	"service":       "service.name",
+	"environment":   "deployment.environment.name",
+	"build_version": "service.version",
+	"build_commit":  "build.commit",
+	"build_time":    "build.time",
+	"go_version":    "process.runtime.version",
+	"go_maxprocs":   "process.runtime.go.maxprocs",
+	"num_cpu":       "host.cpu.count",
+	"num_goroutine": "process.runtime.go.goroutines",
+}
+
+// function 'NewOTLPHandler' creates a new 'HTTPHandler' that exports records to an OTLP/HTTP collector,
+// the endpoint is the collector base url, the logs path "/v1/logs" is appended when it has no path
+func NewOTLPHandler(endpoint string) *HTTPHandler {
+	if u, err := url.Parse(endpoint); err == nil && (u.Path == "" || u.Path == "/") {
+		u.Path = "/v1/logs"
+		endpoint = u.String()
+	}
+	return &HTTPHandler{URL: endpoint, Encoder: &OTLPEncoder{}}
+}
+
+// struct 'OTLPEncoder' implements 'BodyEncoder' interface,
+// it converts records into the OpenTelemetry logs data model encoded as OTLP/JSON,
+// records are grouped by resource which is built from the fields listed in 'otlpResourceKeys',
+// 'Resource' adds static resource attributes to every batch
+type OTLPEncoder struct {
+	ScopeName    string
+	ScopeVersion string
+	Resource     map[string]string
+}
+
+// struct 'otlpRequest' represents the body of an OTLP logs export request
+type otlpRequest struct {
+	ResourceLogs []*otlpResourceLogs `json:"resourceLogs"`
+}
+
+// struct 'otlpResourceLogs' represents the logs produced by a single resource
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+// struct 'otlpResource' represents the entity producing logs
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+// struct 'otlpScopeLogs' represents the logs produced by an instrumentation scope
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+// struct 'otlpScope' represents an instrumentation scope
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// struct 'otlpLogRecord' represents a single record of the OpenTelemetry logs data model
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+	TraceId              string         `json:"traceId,omitempty"`
+	SpanId               string         `json:"spanId,omitempty"`
+}
+
+// struct 'otlpKeyValue' represents an attribute
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+// struct 'otlpAnyValue' represents an attribute value, exactly one member is set
+type otlpAnyValue struct {
+	StringValue *string         `json:"stringValue,omitempty"`
+	BoolValue   *bool           `json:"boolValue,omitempty"`
+	IntValue    *string         `json:"intValue,omitempty"`
+	DoubleValue *float64        `json:"doubleValue,omitempty"`
+	ArrayValue  *otlpArrayValue `json:"arrayValue,omitempty"`
+	KvlistValue *otlpKvlist     `json:"kvlistValue,omitempty"`
+}
+
+// struct 'otlpArrayValue' represents an array attribute value
+type otlpArrayValue struct {
+	Values []otlpAnyValue `json:"values"`
+}
+
+// struct 'otlpKvlist' represents a map attribute value
+type otlpKvlist struct {
+	Values []otlpKeyValue `json:"values"`
+}
+
+// function 'Encode' encodes the given batch of records as an OTLP/JSON logs export request
+func (e *OTLPEncoder) Encode(records []logger.Record) ([]byte, error) {
+	scope := otlpScope{Name: e.ScopeName, Version: e.ScopeVersion}
+	if scope.Name == "" {
+		scope.Name = defaultOTLPScope
+	}
+
+	req := otlpRequest{}
+	resources := make(map[string]*otlpResourceLogs)
+	observed := strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	for _, r := range records {
+		resource, attrs := e.split(r)
+		id := labelSetId(resource)
+		rl, ok := resources[id]
+		if !ok {
+			rl = &otlpResourceLogs{
+				Resource:  otlpResource{Attributes: stringAttributes(resource)},
+				ScopeLogs: []otlpScopeLogs{{Scope: scope}},
+			}
+			resources[id] = rl
+			req.ResourceLogs = append(req.ResourceLogs, rl)
+		}
+
+		rec := otlpLogRecord{
+			TimeUnixNano:         strconv.FormatInt(r.Timestamp.UnixNano(), 10),
+			ObservedTimeUnixNano: observed,
+			SeverityNumber:       otlpSeverity(r.Level),
+			SeverityText:         strings.ToUpper(r.Level.String()),
+			Body:                 anyValue(r.Message),
+			Attributes:           attrs,
+		}
+		rec.TraceId, rec.SpanId = traceContext(r)
+		rl.ScopeLogs[0].LogRecords = append(rl.ScopeLogs[0].LogRecords, rec)
+	}
+
+	return json.Marshal(req)
+}
+
+// function 'ContentType' returns the media type of OTLP/JSON
+func (e *OTLPEncoder) ContentType() string {
+	return "application/json"
+}
+
+// function 'split' splits the record fields into resource attributes and log record attributes
+func (e *OTLPEncoder) split(r logger.Record) (map[string]string, []otlpKeyValue) {
+	resource := make(map[string]string, len(e.Resource)+2)
+	for k, v := range e.Resource {
+		resource[k] = v
+	}
+
+	attrs := make([]otlpKeyValue, 0, len(r.Fields)+2)
+	for _, field := range r.Fields {
+		if key, ok := otlpResourceKeys[field.Key]; ok {
+			resource[key] = fieldValue(field.Value)
+			if field.Key == "go_version" {
+				resource["process.runtime.name"] = "go"
+			}
+			continue
+		}
+		if field.Key == "span_id" {
+			continue
+		}
+		attrs = append(attrs, otlpKeyValue{Key: field.Key, Value: anyValue(field.Value)})
+	}
+
+	// the line number follows the last colon as the path itself may contain colons, e.g. on windows
+	if i := strings.LastIndex(r.Caller, ":"); i >= 0 {
+		file, line := r.Caller[:i], r.Caller[i+1:]
+		attrs = append(attrs, otlpKeyValue{Key: "code.file.path", Value: anyValue(file)})
+		if n, err := strconv.Atoi(line); err == nil {
+			attrs = append(attrs, otlpKeyValue{Key: "code.line.number", Value: anyValue(n)})
+		}
+	}
+
+	return resource, attrs
+}
+
+// function 'otlpSeverity' maps a logging level to an OpenTelemetry severity number
+func otlpSeverity(level logger.Level) int {
+	switch level {
+	case logger.Debug:
+		return 5
+	case logger.Info:
+		return 9
+	case logger.Warn:
+		return 13
+	case logger.Error:
+		return 17
+	case logger.Audit:
+		return 12
+	default:
+		return 0
+	}
+}
+
+// function 'traceContext' returns the hex encoded trace and span ids of the record,
+// the trace id is either a 32 digit hex id or a W3C traceparent value,
+// the span id comes from the traceparent value or from a "span_id" field,
+// ids that are not valid are left empty
+func traceContext(r logger.Record) (string, string) {
+	traceId, spanId := r.TraceId, ""
+	if parts := strings.Split(traceId, "-"); len(parts) == 4 && len(parts[1]) == 32 {
+		traceId, spanId = parts[1], parts[2]
+	}
+	for _, field := range r.Fields {
+		if field.Key == "span_id" {
+			spanId = fieldValue(field.Value)
+		}
+	}
+	return validId(traceId, 16), validId(spanId, 8)
+}
+
+// function 'validId' returns the given id in lowercase if it is a non zero hex id of the given byte size
+func validId(id string, size int) string {
+	b, err := hex.DecodeString(id)
+	if err != nil || len(b) != size {
+		return ""
+	}
+	for _, c := range b {
+		if c != 0 {
+			return strings.ToLower(id)
+		}
+	}
+	return ""
+}
+
+// function 'stringAttributes' converts a string map into attributes sorted by key
+func stringAttributes(m map[string]string) []otlpKeyValue {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]otlpKeyValue, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, otlpKeyValue{Key: k, Value: anyValue(m[k])})
+	}
+	return attrs
+}
+
+// function 'anyValue' converts a field value into an OTLP attribute value,
+// groups become key-value lists, 64 bit integers are encoded as strings as required by OTLP/JSON
+func anyValue(v any) otlpAnyValue {
+	switch val := v.(type) {
+	case nil:
+		return otlpAnyValue{}
+	case string:
+		return otlpAnyValue{StringValue: &val}
+	case bool:
+		return otlpAnyValue{BoolValue: &val}
+	case logger.ErrorValue:
+		return anyValue(val.Object())
+	case []logger.Field:
+		kv := &otlpKvlist{Values: make([]otlpKeyValue, 0, len(val))}
+		for _, field := range val {
+			kv.Values = append(kv.Values, otlpKeyValue{Key: field.Key, Value: anyValue(field.Value)})
+		}
+		return otlpAnyValue{KvlistValue: kv}
+	case error:
+		s := val.Error()
+		return otlpAnyValue{StringValue: &s}
+	case fmt.Stringer:
+		s := val.String()
+		return otlpAnyValue{StringValue: &s}
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s := strconv.FormatInt(rv.Int(), 10)
+		return otlpAnyValue{IntValue: &s}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s := strconv.FormatUint(rv.Uint(), 10)
+		return otlpAnyValue{IntValue: &s}
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		return otlpAnyValue{DoubleValue: &f}
+	case reflect.Slice, reflect.Array:
+		arr := &otlpArrayValue{Values: make([]otlpAnyValue, 0, rv.Len())}
+		for i := 0; i < rv.Len(); i++ {
+			arr.Values = append(arr.Values, anyValue(rv.Index(i).Interface()))
+		}
+		return otlpAnyValue{ArrayValue: arr}
+	case reflect.Map:
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		kv := &otlpKvlist{Values: make([]otlpKeyValue, 0, len(keys))}
+		for _, k := range keys {
+			kv.Values = append(kv.Values, otlpKeyValue{Key: fmt.Sprint(k.Interface()), Value: anyValue(rv.MapIndex(k).Interface())})
+		}
+		return otlpAnyValue{KvlistValue: kv}
+	default:
+		s := fmt.Sprintf("%v", v)
+		return otlpAnyValue{StringValue: &s}
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+func TestOTLPHandlerExportsToCollector(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []otlpRequest
+		paths    []string
+	)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body otlpRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Errorf("invalid OTLP/JSON body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		requests = append(requests, body)
+		paths = append(paths, req.URL.Path)
+		mu.Unlock()
+	}))
+	defer collector.Close()
+
+	h := NewOTLPHandler(collector.URL)
+	h.FlushInterval = time.Hour
+	r := logger.Record{
+		Level:     logger.Warn,
+		Message:   "slow query",
+		TraceId:   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		Caller:    `C:\src\app\db.go:42`,
+		Timestamp: time.Unix(1700000000, 5),
+		Fields: []logger.Field{
+			logger.String("service", "api"),
+			logger.Int("rows", 3),
+			logger.Group("db", logger.String("system", "postgres"), logger.Group("pool", logger.Int("size", 10))),
+		},
+	}
+	if err := h.Handle(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(requests) != 1 || paths[0] != "/v1/logs" {
+		t.Fatalf("got %d requests to %v, want one to /v1/logs", len(requests), paths)
+	}
+	rl := requests[0].ResourceLogs
+	if len(rl) != 1 || len(rl[0].ScopeLogs) != 1 || len(rl[0].ScopeLogs[0].LogRecords) != 1 {
+		t.Fatalf("unexpected request %+v", requests[0])
+	}
+	if attrs := rl[0].Resource.Attributes; len(attrs) != 1 || attrs[0].Key != "service.name" || *attrs[0].Value.StringValue != "api" {
+		t.Errorf("unexpected resource attributes %+v", attrs)
+	}
+
+	rec := rl[0].ScopeLogs[0].LogRecords[0]
+	if rec.TimeUnixNano != "1700000000000000005" || rec.SeverityNumber != 13 || rec.SeverityText != "WARN" || *rec.Body.StringValue != "slow query" {
+		t.Errorf("unexpected record header %+v", rec)
+	}
+	if rec.TraceId != "4bf92f3577b34da6a3ce929d0e0e4736" || rec.SpanId != "00f067aa0ba902b7" {
+		t.Errorf("got trace %q span %q", rec.TraceId, rec.SpanId)
+	}
+
+	attrs := map[string]otlpAnyValue{}
+	for _, kv := range rec.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if v := attrs["rows"]; v.IntValue == nil || *v.IntValue != "3" {
+		t.Errorf("got rows %+v", v)
+	}
+	if v := attrs["code.file.path"]; v.StringValue == nil || *v.StringValue != `C:\src\app\db.go` {
+		t.Errorf("got file path %+v", v)
+	}
+	if v := attrs["code.line.number"]; v.IntValue == nil || *v.IntValue != "42" {
+		t.Errorf("got line number %+v", v)
+	}
+
+	db := attrs["db"].KvlistValue
+	if db == nil || len(db.Values) != 2 || db.Values[0].Key != "system" || *db.Values[0].Value.StringValue != "postgres" {
+		t.Fatalf("group was not encoded as kvlistValue: %+v", attrs["db"])
+	}
+	pool := db.Values[1]
+	if pool.Key != "pool" || pool.Value.KvlistValue == nil || *pool.Value.KvlistValue.Values[0].Value.IntValue != "10" {
+		t.Errorf("nested group was not encoded as kvlistValue: %+v", pool)
+	}
+}
@@ -0,0 +1,13 @@
+package handlers
+
+import "github.com/eskandaridanial/go-starter-kit/foundation/logger"
+
+// function 'init' registers the handlers usable in a 'logger.Config'
+func init() {
+	logger.RegisterHandler("console", func(c logger.HandlerConfig, f logger.Formatter) (logger.Handler, error) {
+		return &ConsoleHandler{Formatter: f}, nil
+	})
+	logger.RegisterHandler("file", func(c logger.HandlerConfig, f logger.Formatter) (logger.Handler, error) {
+		return OpenFileHandler(c.Path, f)
+	})
+}
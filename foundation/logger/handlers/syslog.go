@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+// type 'SyslogFormat' represents the wire format of a syslog message
+type SyslogFormat int
+
+// constants 'RFC5424' and 'RFC3164' are syslog message formats,
+// 'RFC5424' is the modern format that supports structured data,
+// 'RFC3164' is the legacy BSD format understood by every syslog daemon
+const (
+	RFC5424 SyslogFormat = iota
+	RFC3164
+)
+
+// type 'Facility' represents a syslog facility
+type Facility int
+
+// constants for syslog facilities as defined in RFC 5424 section 6.2.1
+const (
+	FacilityKern Facility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLpr
+	FacilityNews
+	FacilityUucp
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFtp
+	FacilityNtp
+	FacilityAudit
+	FacilityAlert
+	FacilityClock
+	FacilityLocal0
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// constant 'defaultStructuredDataId' is the SD-ID used for record fields in case of missing id,
+// 32473 is the private enterprise number reserved for documentation by RFC 5612
+const defaultStructuredDataId = "fields@32473"
+
+// variable 'localSyslogPaths' are the unix sockets probed when no address is configured
+var localSyslogPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// struct 'SyslogHandler' implements 'Handler' interface,
+// it writes records to a syslog daemon over a local unix socket, UDP or TCP,
+// 'Network' is one of "unix", "unixgram", "udp" or "tcp", an empty network means the local syslog socket,
+// TCP connections use octet-counting framing as defined in RFC 6587
+type SyslogHandler struct {
+	Network          string
+	Address          string
+	Format           SyslogFormat
+	Facility         Facility
+	AppName          string
+	Hostname         string
+	ProcId           string
+	MsgId            string
+	StructuredDataId string
+	mu               sync.Mutex
+	conn             net.Conn
+	network          string
+}
+
+// function 'Handle' handles the given record by formatting it as a syslog message and writing it to the daemon
+func (h *SyslogHandler) Handle(ctx context.Context, r logger.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	msg := h.format(r)
+
+	// a broken connection is redialed once before giving up on the record
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if h.conn == nil {
+			if err = h.connect(); err != nil {
+				return fmt.Errorf("syslog connect: %w", err)
+			}
+		}
+		if _, err = h.conn.Write(h.frame(msg)); err == nil {
+			return nil
+		}
+		h.conn.Close()
+		h.conn = nil
+	}
+	return fmt.Errorf("syslog write: %w", err)
+}
+
+// function 'Close' closes the connection to the syslog daemon
+func (h *SyslogHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.conn == nil {
+		return nil
+	}
+	err := h.conn.Close()
+	h.conn = nil
+	return err
+}
+
+// function 'connect' dials the configured syslog endpoint,
+// if no network is configured, it probes the well-known local sockets
+func (h *SyslogHandler) connect() error {
+	if h.Network != "" {
+		conn, err := net.Dial(h.Network, h.Address)
+		if err != nil {
+			return err
+		}
+		h.conn, h.network = conn, h.Network
+		return nil
+	}
+
+	paths := localSyslogPaths
+	if h.Address != "" {
+		paths = []string{h.Address}
+	}
+	for _, path := range paths {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.Dial(network, path)
+			if err == nil {
+				h.conn, h.network = conn, network
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("no local syslog socket found in %v", paths)
+}
+
+// function 'frame' applies the transport framing to the given message,
+// datagram transports carry one message per packet and need no framing
+func (h *SyslogHandler) frame(msg []byte) []byte {
+	switch h.network {
+	case "tcp", "tcp4", "tcp6":
+		return append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	case "unix":
+		return append(msg, '\n')
+	default:
+		return msg
+	}
+}
+
+// function 'format' formats the given record using the configured syslog format
+func (h *SyslogHandler) format(r logger.Record) []byte {
+	pri := int(h.Facility)*8 + r.Level.SyslogSeverity()
+	if h.Format == RFC3164 {
+		return h.formatRFC3164(pri, r)
+	}
+	return h.formatRFC5424(pri, r)
+}
+
+// function 'formatRFC5424' formats the given record as
+// "<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG"
+func (h *SyslogHandler) formatRFC5424(pri int, r logger.Record) []byte {
+	var sb strings.Builder
+	sb.WriteString("<")
+	sb.WriteString(strconv.Itoa(pri))
+	sb.WriteString(">1 ")
+	sb.WriteString(r.Timestamp.Format("2006-01-02T15:04:05.000000Z07:00"))
+	sb.WriteByte(' ')
+	sb.WriteString(headerValue(h.hostname(), 255))
+	sb.WriteByte(' ')
+	sb.WriteString(headerValue(h.appName(), 48))
+	sb.WriteByte(' ')
+	sb.WriteString(headerValue(h.procId(), 128))
+	sb.WriteByte(' ')
+	sb.WriteString(headerValue(h.MsgId, 32))
+	sb.WriteByte(' ')
+	h.writeStructuredData(&sb, r)
+	if r.Message != "" {
+		sb.WriteByte(' ')
+		sb.WriteString(r.Message)
+	}
+	return []byte(sb.String())
+}
+
+// function 'writeStructuredData' writes the record fields as a single SD-ELEMENT,
+// it writes the nil value when the record has nothing to carry
+func (h *SyslogHandler) writeStructuredData(sb *strings.Builder, r logger.Record) {
+	params := make([]logger.Field, 0, len(r.Fields)+2)
+	if traceId := r.KnownTraceId(); traceId != "" {
+		params = append(params, logger.String("trace_id", traceId))
+	}
+	if r.Caller != "" {
+		params = append(params, logger.String("caller", r.Caller))
+	}
+	params = append(params, r.Fields...)
+
+	if len(params) == 0 {
+		sb.WriteByte('-')
+		return
+	}
+
+	id := h.StructuredDataId
+	if id == "" {
+		id = defaultStructuredDataId
+	}
+
+	sb.WriteByte('[')
+	sb.WriteString(sdName(id))
+	for _, p := range params {
+		sb.WriteByte(' ')
+		sb.WriteString(sdName(p.Key))
+		sb.WriteString(`="`)
+		sb.WriteString(sdEscape(fieldValue(p.Value)))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte(']')
+}
+
+// function 'formatRFC3164' formats the given record as "<PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG",
+// the legacy format has no structured data so fields are appended to the message as key=value pairs
+func (h *SyslogHandler) formatRFC3164(pri int, r logger.Record) []byte {
+	var sb strings.Builder
+	sb.WriteString("<")
+	sb.WriteString(strconv.Itoa(pri))
+	sb.WriteString(">")
+	sb.WriteString(r.Timestamp.Format(time.Stamp))
+	sb.WriteByte(' ')
+	sb.WriteString(headerValue(h.hostname(), 255))
+	sb.WriteByte(' ')
+	sb.WriteString(h.appName())
+	if procId := h.procId(); procId != "" {
+		sb.WriteString("[")
+		sb.WriteString(procId)
+		sb.WriteString("]")
+	}
+	sb.WriteString(": ")
+	sb.WriteString(r.Message)
+	for _, field := range r.Fields {
+		sb.WriteByte(' ')
+		sb.WriteString(field.Key)
+		sb.WriteByte('=')
+		sb.WriteString(fieldValue(field.Value))
+	}
+	return []byte(sb.String())
+}
+
+// function 'hostname' returns the configured hostname or the name reported by the kernel
+func (h *SyslogHandler) hostname() string {
+	if h.Hostname != "" {
+		return h.Hostname
+	}
+	name, _ := os.Hostname()
+	return name
+}
+
+// function 'appName' returns the configured app-name or the base name of the running binary
+func (h *SyslogHandler) appName() string {
+	if h.AppName != "" {
+		return h.AppName
+	}
+	return filepath.Base(os.Args[0])
+}
+
+// function 'procId' returns the configured procid or the current process id
+func (h *SyslogHandler) procId() string {
+	if h.ProcId != "" {
+		return h.ProcId
+	}
+	return strconv.Itoa(os.Getpid())
+}
+
+// function 'headerValue' returns a header field limited to printable US-ASCII and the given length,
+// it returns the nil value "-" for empty fields
+func headerValue(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, s)
+	if len(s) > max {
+		s = s[:max]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// function 'sdName' sanitizes an SD-NAME, it removes '=', ' ', ']', '"' and non printable characters
+func sdName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > 32 && !strings.Contains(s, "@") {
+		s = s[:32]
+	}
+	return s
+}
+
+// function 'sdEscape' escapes '"', '\' and ']' in a PARAM-VALUE
+func sdEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
+
+// function 'fieldValue' returns the string representation of a field value,
+// composite values are encoded as JSON so they remain machine readable
+func fieldValue(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case logger.ErrorValue:
+		return fieldValue(val.Object())
+	case []logger.Field:
+		return fieldValue(logger.GroupMap(val))
+	case map[string]any, []any:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprintf("%v", val)
+		}
+		return string(b)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
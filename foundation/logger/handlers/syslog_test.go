@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+// variable 'syslogTime' is the fixed timestamp of the records used by the syslog tests
+var syslogTime = time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+func syslogRecord(level logger.Level, msg string, fields ...logger.Field) logger.Record {
+	return logger.Record{Level: level, Message: msg, Timestamp: syslogTime, Fields: fields}
+}
+
+// function 'readOctetCounted' reads one RFC 6587 octet-counted frame
+func readOctetCounted(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	size, err := r.ReadString(' ')
+	if err != nil {
+		t.Fatalf("reading frame length: %v", err)
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(size, " "))
+	if err != nil {
+		t.Fatalf("invalid frame length %q: %v", size, err)
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		t.Fatalf("reading frame: %v", err)
+	}
+	return string(msg)
+}
+
+func TestSyslogHandlerUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	h := &SyslogHandler{
+		Network:  "udp",
+		Address:  conn.LocalAddr().String(),
+		Facility: FacilityLocal0,
+		AppName:  "app",
+		Hostname: "host",
+		ProcId:   "42",
+	}
+	defer h.Close()
+
+	if err := h.Handle(context.Background(), syslogRecord(logger.Error, "disk full", logger.String("path", `/var/"x"]`))); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// local0 (16) * 8 + error (3)
+	want := `<131>1 2024-05-06T07:08:09.000000Z host app 42 - [fields@32473 path="/var/\"x\"\]"] disk full`
+	if got := string(buf[:n]); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestSyslogHandlerRFC3164(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	h := &SyslogHandler{
+		Network:  "udp",
+		Address:  conn.LocalAddr().String(),
+		Format:   RFC3164,
+		Facility: FacilityDaemon,
+		AppName:  "app",
+		Hostname: "host",
+		ProcId:   "42",
+	}
+	defer h.Close()
+
+	if err := h.Handle(context.Background(), syslogRecord(logger.Debug, "tick", logger.Int("n", 1))); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// daemon (3) * 8 + debug (7)
+	want := "<31>May  6 07:08:09 host app[42]: tick n=1"
+	if got := string(buf[:n]); got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestSyslogHandlerTCPFramingAndReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	conns := make(chan net.Conn, 2)
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns <- c
+		}
+	}()
+
+	h := &SyslogHandler{Network: "tcp", Address: ln.Addr().String(), Facility: FacilityUser, AppName: "app", Hostname: "host", ProcId: "1"}
+	defer h.Close()
+	ctx := context.Background()
+
+	for _, msg := range []string{"first", "second"} {
+		if err := h.Handle(ctx, syslogRecord(logger.Info, msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first := <-conns
+	r := bufio.NewReader(first)
+	first.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for _, msg := range []string{"first", "second"} {
+		got := readOctetCounted(t, r)
+		// user (1) * 8 + info (6)
+		if !strings.HasPrefix(got, "<14>1 ") || !strings.HasSuffix(got, " - "+msg) {
+			t.Errorf("unexpected frame %q", got)
+		}
+	}
+
+	// the server drops the connection, the handler is expected to redial
+	first.Close()
+	deadline := time.After(5 * time.Second)
+	for {
+		// writes to a connection closed by the peer may succeed until the reset arrives
+		_ = h.Handle(ctx, syslogRecord(logger.Warn, "after reconnect"))
+		select {
+		case second := <-conns:
+			defer second.Close()
+			second.SetReadDeadline(time.Now().Add(5 * time.Second))
+			got := readOctetCounted(t, bufio.NewReader(second))
+			if !strings.HasPrefix(got, "<12>1 ") || !strings.HasSuffix(got, "after reconnect") {
+				t.Errorf("unexpected frame after reconnect %q", got)
+			}
+			return
+		case <-deadline:
+			t.Fatal("handler did not reconnect")
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
+func TestSyslogHandlerConnectError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	h := &SyslogHandler{Network: "tcp", Address: addr}
+	if err := h.Handle(context.Background(), syslogRecord(logger.Info, "lost")); err == nil {
+		t.Fatal("expected an error when the daemon is unreachable")
+	}
+}
+
+func TestSyslogStructuredData(t *testing.T) {
+	h := &SyslogHandler{Facility: FacilityUser, AppName: "app", Hostname: "host", ProcId: "42"}
+
+	// records logged without a trace id carry the placeholder of the logger
+	untraced := syslogRecord(logger.Info, "created",
+		logger.Group("card", logger.String("brand", "visa"), logger.Int("last4", 4242)))
+	untraced.TraceId = "default_trace_id"
+	want := `<14>1 2024-05-06T07:08:09.000000Z host app 42 - [fields@32473 card="{\"brand\":\"visa\",\"last4\":4242}"] created`
+	if got := string(h.format(untraced)); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	traced := syslogRecord(logger.Warn, "slow")
+	traced.TraceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+	want = `<12>1 2024-05-06T07:08:09.000000Z host app 42 - [fields@32473 trace_id="4bf92f3577b34da6a3ce929d0e0e4736"] slow`
+	if got := string(h.format(traced)); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
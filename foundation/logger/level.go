@@ -1,9 +1,14 @@
 package logger
 
+import (
+	"fmt"
+	"strings"
+)
+
 // type 'Level' represents a logging level
 type Level int
 
-// constants 'Debug', 'Info', 'Warn', 'Error' are logging levels
+// constants 'Debug', 'Info', 'Warn', 'Error' are logging levels ordered by severity
 const (
 	Debug Level = iota
 	Info
@@ -11,6 +16,11 @@ const (
 	Error
 )
 
+// constant 'Audit' is the always-on level of audit records written by 'Auditor', it bypasses level filtering,
+// it is not a severity and sorts below 'Debug' so thresholds such as 'level >= Error' never match audit records,
+// it cannot be parsed by 'ParseLevel' so it cannot be configured as a minimum level
+const Audit Level = -1
+
 // function 'String' returns the string representation of the logging level
 func (l Level) String() string {
 	switch l {
@@ -22,7 +32,53 @@ func (l Level) String() string {
 		return "warn"
 	case Error:
 		return "error"
+	case Audit:
+		return "audit"
 	default:
 		return "unknown"
 	}
 }
+
+// function 'SyslogSeverity' returns the syslog severity of the logging level as defined by RFC 5424,
+// audit records are notices
+func (l Level) SyslogSeverity() int {
+	switch l {
+	case Debug:
+		return 7
+	case Info:
+		return 6
+	case Warn:
+		return 4
+	case Error:
+		return 3
+	default:
+		return 5
+	}
+}
+
+// function 'ParseLevel' returns the logging level with the given name, it is case insensitive
+// and also accepts "warning" for 'Warn', "audit" is rejected as it is not a minimum level
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return Debug, nil
+	case "info":
+		return Info, nil
+	case "warn", "warning":
+		return Warn, nil
+	case "error":
+		return Error, nil
+	case "audit":
+		return Info, fmt.Errorf("level %q is reserved for audit records", name)
+	default:
+		return Info, fmt.Errorf("unknown level %q", name)
+	}
+}
+
+// function 'parseRecordLevel' returns the level of a decoded record, unlike 'ParseLevel' it accepts "audit"
+func parseRecordLevel(name string) (Level, error) {
+	if strings.EqualFold(strings.TrimSpace(name), Audit.String()) {
+		return Audit, nil
+	}
+	return ParseLevel(name)
+}
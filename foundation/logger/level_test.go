@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseLevelRejectsAudit(t *testing.T) {
+	if _, err := ParseLevel("audit"); err == nil {
+		t.Error("ParseLevel accepted \"audit\"")
+	}
+	if err := (Config{Level: "AUDIT"}).Validate(); err == nil {
+		t.Error("Validate accepted level \"AUDIT\"")
+	}
+	if _, err := ParseLevelOverrides("db=audit"); err == nil {
+		t.Error("ParseLevelOverrides accepted \"audit\"")
+	}
+	if level, err := parseRecordLevel("audit"); err != nil || level != Audit {
+		t.Errorf("got %v, %v", level, err)
+	}
+}
+
+func TestAuditIsOutsideSeverityOrdering(t *testing.T) {
+	if Audit >= Error || Audit >= Debug {
+		t.Errorf("audit sorts with the severities")
+	}
+
+	h := &collectingHandler{}
+	l := NewLogger(WithHandler(h), WithLevel(Error), WithStacktraceLevel(Error), WithSynchronous())
+	NewAuditor(l).Log(context.Background(), AuditEvent{Actor: "bob", Action: "login"})
+	l.Close()
+
+	records := h.records
+	if len(records) != 1 || records[0].Level != Audit {
+		t.Fatalf("got %v", records)
+	}
+	if len(records[0].Stack) > 0 {
+		t.Errorf("audit record captured a stack as if it were an error")
+	}
+}
@@ -0,0 +1,252 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// struct 'LogfmtFormatter' implements 'Formatter' interface,
+// it formats records as logfmt lines that Heroku style and Loki logfmt parsers can read back,
+// nested 'Map', 'Group' and 'Err' fields are flattened into dotted keys, array items are keyed by index,
+// 'Timestamp' encodes the timestamp which is written under the "time" key by default
+type LogfmtFormatter struct {
+	Timestamp TimestampEncoder
+}
+
+// function 'NewLogfmtFormatter' creates a new 'LogfmtFormatter'
+func NewLogfmtFormatter() *LogfmtFormatter {
+	return &LogfmtFormatter{}
+}
+
+// function 'Format' formats the given record as a logfmt line
+func (f *LogfmtFormatter) Format(r Record) []byte {
+	var buf bytes.Buffer
+
+	writeLogfmtPair(&buf, f.Timestamp.key("time"), f.Timestamp.String(r.Timestamp))
+	writeLogfmtPair(&buf, "level", r.Level.String())
+	writeLogfmtPair(&buf, "msg", r.Message)
+	if r.Caller != "" {
+		writeLogfmtPair(&buf, "caller", r.Caller)
+	}
+	if traceId := r.KnownTraceId(); traceId != "" {
+		writeLogfmtPair(&buf, "trace_id", traceId)
+	}
+	for _, field := range r.Fields {
+		writeLogfmtField(&buf, field.Key, field.Value)
+	}
+	if len(r.Stack) > 0 {
+		writeLogfmtPair(&buf, "stacktrace", StackString(r.Stack))
+	}
+
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// function 'writeLogfmtField' writes a field, nested values are flattened with dotted keys
+func writeLogfmtField(buf *bytes.Buffer, key string, value any) {
+	switch val := value.(type) {
+	case []Field:
+		for _, field := range val {
+			writeLogfmtField(buf, key+"."+field.Key, field.Value)
+		}
+	case ErrorValue:
+		writeLogfmtField(buf, key, val.Object())
+	case []any:
+		for i, item := range val {
+			writeLogfmtField(buf, key+"."+strconv.Itoa(i), item)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			writeLogfmtField(buf, key+"."+k, val[k])
+		}
+	default:
+		writeLogfmtPair(buf, key, logfmtValue(val))
+	}
+}
+
+// function 'writeLogfmtPair' writes a single key=value pair preceded by a separator when needed
+func writeLogfmtPair(buf *bytes.Buffer, key, value string) {
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+	writeLogfmtKey(buf, key)
+	buf.WriteByte('=')
+	if needsLogfmtQuoting(value) {
+		writeLogfmtQuoted(buf, value)
+	} else {
+		buf.WriteString(value)
+	}
+}
+
+// function 'logfmtValue' returns the string representation of a field value
+func logfmtValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return val
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case error:
+		return val.Error()
+	case fmt.Stringer:
+		return val.String()
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// function 'writeLogfmtKey' writes a key, characters not allowed in keys are replaced by '_'
+func writeLogfmtKey(buf *bytes.Buffer, key string) {
+	if key == "" {
+		buf.WriteByte('_')
+		return
+	}
+	for _, c := range key {
+		if c <= ' ' || c == '=' || c == '"' || c == utf8.RuneError {
+			buf.WriteByte('_')
+			continue
+		}
+		buf.WriteRune(c)
+	}
+}
+
+// function 'needsLogfmtQuoting' reports whether a value has to be quoted,
+// empty values and values containing spaces, '=', '"', '\' or control characters including DEL are quoted
+func needsLogfmtQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, c := range s {
+		if c <= ' ' || c == 0x7f || c == '=' || c == '"' || c == '\\' || c == utf8.RuneError {
+			return true
+		}
+	}
+	return false
+}
+
+// function 'writeLogfmtQuoted' writes a double quoted value escaping '"', '\' and control characters including DEL
+func writeLogfmtQuoted(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, c := range s {
+		switch c {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < ' ' || c == 0x7f {
+				fmt.Fprintf(buf, `\u%04x`, c)
+				continue
+			}
+			buf.WriteRune(c)
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// function 'ParseLogfmt' parses a logfmt line into fields holding string values,
+// it is the inverse of 'LogfmtFormatter' and is used to read formatted records back
+func ParseLogfmt(line []byte) ([]Field, error) {
+	var fields []Field
+	s := string(bytes.TrimRight(line, "\r\n"))
+
+	for i := 0; i < len(s); {
+		if s[i] == ' ' {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(s) && s[i] != '=' && s[i] != ' ' {
+			i++
+		}
+		key := s[start:i]
+		if key == "" {
+			return nil, fmt.Errorf("logfmt: empty key at offset %d", start)
+		}
+		if i == len(s) || s[i] == ' ' {
+			// a key without '=' is a flag with no value
+			fields = append(fields, Field{Key: key, Value: ""})
+			continue
+		}
+		i++
+
+		if i < len(s) && s[i] == '"' {
+			value, n, err := unquoteLogfmt(s[i:])
+			if err != nil {
+				return nil, fmt.Errorf("logfmt: value of %q: %w", key, err)
+			}
+			fields = append(fields, Field{Key: key, Value: value})
+			i += n
+			continue
+		}
+
+		start = i
+		for i < len(s) && s[i] != ' ' {
+			i++
+		}
+		fields = append(fields, Field{Key: key, Value: s[start:i]})
+	}
+
+	return fields, nil
+}
+
+// function 'unquoteLogfmt' decodes the quoted value at the start of s,
+// it returns the value and the number of bytes consumed
+func unquoteLogfmt(s string) (string, int, error) {
+	var buf bytes.Buffer
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"':
+			return buf.String(), i + 1, nil
+		case '\\':
+			i++
+			if i == len(s) {
+				return "", 0, errors.New("unterminated escape")
+			}
+			switch s[i] {
+			case '"', '\\':
+				buf.WriteByte(s[i])
+			case 'n':
+				buf.WriteByte('\n')
+			case 'r':
+				buf.WriteByte('\r')
+			case 't':
+				buf.WriteByte('\t')
+			case 'u':
+				if i+4 >= len(s) {
+					return "", 0, errors.New("short unicode escape")
+				}
+				code, err := strconv.ParseUint(s[i+1:i+5], 16, 32)
+				if err != nil {
+					return "", 0, fmt.Errorf("invalid unicode escape: %w", err)
+				}
+				buf.WriteRune(rune(code))
+				i += 4
+			default:
+				return "", 0, fmt.Errorf("invalid escape '\\%c'", s[i])
+			}
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return "", 0, errors.New("unterminated quoted value")
+}
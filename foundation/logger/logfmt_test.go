@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLogfmtRoundTrip(t *testing.T) {
+	r := Record{
+		Level:     Warn,
+		Message:   `disk "data" is 91% full`,
+		TraceId:   "4bf92f3577b34da6",
+		Caller:    "main.go:12",
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 600, time.UTC),
+		Fields: []Field{
+			String("path", `C:\data dir`),
+			String("empty", ""),
+			String("multi", "line one\nline two\ttabbed\r"),
+			String("unicode", "héllo wörld"),
+			String("control", "bell\x07"),
+			String("delete", "rub\x7f"),
+			String("equals", "a=b"),
+			Int("count", 3),
+			Bool("ok", true),
+			{Key: "nothing", Value: nil},
+			Group("db", String("system", "postgres"), Group("pool", Int("size", 10))),
+			Map("labels", map[string]any{"b": "2", "a": "1"}),
+			{Key: "items", Value: []any{"x", "y z"}},
+			{Key: "bad key", Value: "v"},
+		},
+	}
+
+	line := NewLogfmtFormatter().Format(r)
+	if !bytes.Contains(line, []byte(`delete="rub\u007f"`)) {
+		t.Errorf("DEL is not quoted and escaped in %s", line)
+	}
+	fields, err := ParseLogfmt(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Field{
+		{"time", "2024-01-02T03:04:05.0000006Z"},
+		{"level", "warn"},
+		{"msg", `disk "data" is 91% full`},
+		{"caller", "main.go:12"},
+		{"trace_id", "4bf92f3577b34da6"},
+		{"path", `C:\data dir`},
+		{"empty", ""},
+		{"multi", "line one\nline two\ttabbed\r"},
+		{"unicode", "héllo wörld"},
+		{"control", "bell\x07"},
+		{"delete", "rub\x7f"},
+		{"equals", "a=b"},
+		{"count", "3"},
+		{"ok", "true"},
+		{"nothing", "null"},
+		{"db.system", "postgres"},
+		{"db.pool.size", "10"},
+		{"labels.a", "1"},
+		{"labels.b", "2"},
+		{"items.0", "x"},
+		{"items.1", "y z"},
+		{"bad_key", "v"},
+	}
+	if len(fields) != len(want) {
+		t.Fatalf("got %d fields %v, want %d", len(fields), fields, len(want))
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Errorf("field %d: got %q=%q, want %q=%q", i, fields[i].Key, fields[i].Value, want[i].Key, want[i].Value)
+		}
+	}
+}
+
+func TestLogfmtFlattensErrors(t *testing.T) {
+	err := fmt.Errorf("query: %w", errors.New("timeout"))
+	fields, parseErr := ParseLogfmt(NewLogfmtFormatter().Format(Record{Level: Error, Message: "failed", Fields: []Field{Err(err)}}))
+	if parseErr != nil {
+		t.Fatal(parseErr)
+	}
+
+	got := map[string]any{}
+	for _, f := range fields {
+		got[f.Key] = f.Value
+	}
+	for key, value := range map[string]string{
+		"error.message":          "query: timeout",
+		"error.type":             "*fmt.wrapError",
+		"error.causes.0.message": "timeout",
+	} {
+		if got[key] != value {
+			t.Errorf("got %s=%v, want %q", key, got[key], value)
+		}
+	}
+}
+
+func TestLogfmtOmitsPlaceholderTraceId(t *testing.T) {
+	fields, err := ParseLogfmt(NewLogfmtFormatter().Format(Record{Message: "untraced", TraceId: defaultTraceIdValue}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range fields {
+		if f.Key == "trace_id" {
+			t.Errorf("untraced record written with trace_id=%v", f.Value)
+		}
+	}
+}
+
+func TestParseLogfmtErrors(t *testing.T) {
+	for _, line := range []string{
+		`msg="unterminated`,
+		`msg="bad \q escape"`,
+		`msg="short \u12"`,
+		`=value`,
+	} {
+		if _, err := ParseLogfmt([]byte(line)); err == nil {
+			t.Errorf("expected an error for %s", line)
+		}
+	}
+
+	fields, err := ParseLogfmt([]byte("flag a=1\n"))
+	if err != nil || len(fields) != 2 || fields[0] != (Field{"flag", ""}) || fields[1] != (Field{"a", "1"}) {
+		t.Errorf("got %v, %v", fields, err)
+	}
+}
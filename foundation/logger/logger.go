@@ -2,36 +2,76 @@ package logger
 
 import (
 	"context"
-	"time"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 )
 
 // type 'Logger' represents a message that contains properties required for structured logging
 type Logger struct {
-	Level                Level
-	Handlers             []Handler
-	Fields               []Field
-	Hooks                []Hook
+	Level           Level
+	Handlers        []Handler
+	Fields          []Field
+	Hooks           []Hook
+	name            string
+	callerSkip      int
+	levels          *levelCache
+	dispatcher      *Dispatcher
+	owner           bool
+	ownLevel        bool
+	config          *config
+	onceBuildInfo   []Field
+	onceRuntimeInfo []Field
+}
+
+// struct 'config' represents the configuration shared by a logger and all of its children,
+// children hold a pointer to the configuration of their root so no setting is lost when deriving them
+type config struct {
 	ctx                  context.Context
-	dispatcher           *Dispatcher
-	onceBuildInfo        []Field
-	onceRuntimeInfo      []Field
 	traceIdKey           string
 	bufferSize           int
 	backpressure         BackpressureStrategy
 	numWorkers           int
 	internalErrorHandler func(error)
+	clock                Clock
+	callerFormat         CallerFormat
+	stacktrace           bool
+	stacktraceLevel      Level
+	stacktraceDepth      int
+	levels               atomic.Pointer[LevelOverrides]
+	level                atomic.Pointer[Level]
+	handlersMu           sync.RWMutex
+	synchronous          bool
+	wal                  *WALConfig
+	walMu                sync.Mutex
+	walDirs              map[string]int
+	errs                 []error
 }
 
 // function 'NewLogger' creates a new logger instance with the given options
 func NewLogger(opts ...Option) *Logger {
 	l := &Logger{
-		Level: Info,
+		Level:  Info,
+		levels: &levelCache{},
+		config: &config{clock: systemClock{}},
 	}
 	for _, o := range opts {
 		o(l)
 	}
 
-	l.dispatcher = NewDispatcher(l.Handlers, l.Hooks, l.numWorkers, l.bufferSize, l.backpressure, l.internalErrorHandler)
+	l.dispatcher = l.newDispatcher()
+	l.owner = true
+	if c := l.config.wal; c != nil {
+		if err := l.dispatcher.OpenWAL(*c); err != nil {
+			l.config.errs = append(l.config.errs, fmt.Errorf("running without write-ahead log: %w", err))
+		}
+	}
+	// options cannot return errors so their failures are reported once the dispatcher exists
+	for _, err := range l.config.errs {
+		l.dispatcher.reportInternalError(err)
+	}
+	l.config.errs = nil
 
 	if len(l.onceBuildInfo) > 0 {
 		l.Info("build information", l.onceBuildInfo...)
@@ -46,50 +86,176 @@ func NewLogger(opts ...Option) *Logger {
 	return l
 }
 
+// function 'newDispatcher' creates a dispatcher for the handlers and hooks of the logger
+func (l *Logger) newDispatcher() *Dispatcher {
+	c := l.config
+	if c.synchronous {
+		return NewSyncDispatcher(l.Handlers, l.Hooks, c.internalErrorHandler)
+	}
+	return NewDispatcher(l.Handlers, l.Hooks, c.numWorkers, c.bufferSize, c.backpressure, c.internalErrorHandler)
+}
+
+// function 'child' creates a copy of the logger sharing its configuration and dispatcher,
+// the dispatcher stays owned by the logger it was created for
+func (l *Logger) child() *Logger {
+	l.config.handlersMu.RLock()
+	c := *l
+	l.config.handlersMu.RUnlock()
+	c.levels = &levelCache{}
+	c.owner = false
+	return &c
+}
+
+// function 'childWAL' returns the write-ahead log configuration of a child with handlers of its own,
+// every child gets a directory below the one of the root named after the child, a suffix is added
+// when the name was used before, so children created in the same order find their segments again after a restart
+func (c *config) childWAL(name string) WALConfig {
+	c.walMu.Lock()
+	defer c.walMu.Unlock()
+
+	base := name
+	if base == "" {
+		base = "handlers"
+	}
+	if c.walDirs == nil {
+		c.walDirs = map[string]int{}
+	}
+	dir := base
+	if n := c.walDirs[base]; n > 0 {
+		dir = fmt.Sprintf("%s-%d", base, n)
+	}
+	c.walDirs[base]++
+
+	child := *c.wal
+	child.Dir = filepath.Join(c.wal.Dir, "children", dir)
+	return child
+}
+
+// function 'With' creates a child logger with the given fields added to the fields of the logger
+func (l *Logger) With(fields ...Field) *Logger {
+	c := l.child()
+	c.Fields = make([]Field, 0, len(l.Fields)+len(fields))
+	c.Fields = append(c.Fields, l.Fields...)
+	c.Fields = append(c.Fields, fields...)
+	return c
+}
+
 // function 'WithField' creates a new logger instance with the given field added to the logger
 // it is used after 'Logger' is initialized to add additional fields to the logger
 func (l *Logger) WithField(field Field) *Logger {
-	newFields := make([]Field, len(l.Fields)+1)
-	copy(newFields, l.Fields)
-	newFields[len(l.Fields)] = field
+	return l.With(field)
+}
 
-	return &Logger{
-		Level:      l.Level,
-		Handlers:   l.Handlers,
-		Fields:     newFields,
-		Hooks:      l.Hooks,
-		ctx:        l.ctx,
-		dispatcher: l.dispatcher,
+// function 'Named' creates a child logger for the given component,
+// names of nested children are joined with dots and written as the "logger" field, e.g. "auth.db.pool"
+func (l *Logger) Named(name string) *Logger {
+	c := l.child()
+	if l.name != "" && name != "" {
+		c.name = l.name + "." + name
+	} else if name != "" {
+		c.name = name
 	}
+	return c
+}
+
+// function 'Name' returns the hierarchical name of the logger, empty for the root logger
+func (l *Logger) Name() string {
+	return l.name
+}
+
+// function 'WithLevel' creates a child logger with the given minimum logging level,
+// the level is kept when the level of the root is changed by 'SetLevel'
+func (l *Logger) WithLevel(level Level) *Logger {
+	c := l.child()
+	c.Level = level
+	c.ownLevel = true
+	return c
+}
+
+// function 'SetLevel' sets the minimum logging level of the logger and all loggers sharing its configuration,
+// except for children created by 'WithLevel' which keep their own level
+func (l *Logger) SetLevel(level Level) {
+	l.config.level.Store(&level)
+}
+
+// function 'minLevel' returns the minimum logging level of the logger when no level override matches its name
+func (l *Logger) minLevel() Level {
+	if !l.ownLevel {
+		if level := l.config.level.Load(); level != nil {
+			return *level
+		}
+	}
+	return l.Level
+}
+
+// function 'setHandlers' replaces the handlers of the logger and of its dispatcher,
+// children created afterwards see the new handlers and those sharing the dispatcher write to them right away
+func (l *Logger) setHandlers(handlers []Handler) {
+	l.config.handlersMu.Lock()
+	defer l.config.handlersMu.Unlock()
+	l.dispatcher.SetHandlers(handlers)
+	l.Handlers = handlers
+}
+
+// function 'WithHandlers' creates a child logger writing to the given handlers instead of those of the logger,
+// the child gets a dispatcher of its own with the hooks and queue settings of the logger,
+// with a write-ahead log it gets one of its own as well, see 'config.childWAL',
+// the dispatcher runs until the child is closed by its 'Close'
+func (l *Logger) WithHandlers(handlers ...Handler) *Logger {
+	c := l.child()
+	c.Handlers = handlers
+	c.dispatcher = c.newDispatcher()
+	c.owner = true
+	if l.config.wal != nil {
+		if err := c.dispatcher.OpenWAL(l.config.childWAL(l.name)); err != nil {
+			c.dispatcher.reportInternalError(fmt.Errorf("running without write-ahead log: %w", err))
+		}
+	}
+	return c
+}
+
+// function 'AddCallerSkip' creates a new logger instance that skips n additional frames when reporting the caller,
+// it is used by helpers wrapping the logger so the caller of the helper is reported instead of the helper
+func (l *Logger) AddCallerSkip(n int) *Logger {
+	c := l.child()
+	c.callerSkip += n
+	return c
 }
 
 // function 'log' logs a message with the given level and fields
 // it is used by 'Debug', 'Info', 'Warn', 'Error' methods
 func (l *Logger) log(ctx context.Context, level Level, msg string, fields []Field) {
-	if level < l.Level {
+	if !l.Enabled(level) {
 		return
 	}
 
 	var workingCtx context.Context
 	if ctx != nil && ctx != context.TODO() && ctx != context.Background() {
 		workingCtx = ctx
-	} else if l.ctx != nil {
-		workingCtx = l.ctx
+	} else if l.config.ctx != nil {
+		workingCtx = l.config.ctx
 	} else {
 		workingCtx = ctx
 	}
 
-	merged := make([]Field, 0, len(l.Fields)+len(fields))
-	merged = append(merged, l.Fields...)
-	merged = append(merged, fields...)
+	// fields are merged from the least to the most specific so later keys take precedence,
+	// 'Logger.Fields' first, then the fields carried by the context and the fields of the call last
+	var name []Field
+	if l.name != "" {
+		name = []Field{String("logger", l.name)}
+	}
+	merged := mergeFields(name, l.Fields, FieldsFromContext(workingCtx), fields)
 
 	rec := Record{
 		Level:     level,
 		Message:   msg,
-		TraceId:   getTraceId(l.traceIdKey, workingCtx),
-		Caller:    caller(3),
+		TraceId:   getTraceId(l.config.traceIdKey, workingCtx),
+		Caller:    caller(3+l.callerSkip, l.config.callerFormat),
 		Fields:    merged,
-		Timestamp: time.Now(),
+		Timestamp: l.config.clock.Now(),
+	}
+	if l.config.stacktrace && level >= l.config.stacktraceLevel {
+		rec.Stack = captureStack(3+l.callerSkip, l.config.stacktraceDepth)
 	}
 
 	l.dispatcher.Dispatch(workingCtx, rec)
@@ -135,7 +301,12 @@ func (l *Logger) ErrorCtx(ctx context.Context, msg string, fields ...Field) {
 	l.log(ctx, Error, msg, fields)
 }
 
-// function 'Close' closes the logger and releases all resources
+// function 'Close' delivers the queued records and releases the resources of the logger,
+// it is a no-op on children created by 'With', 'Named', 'WithLevel' and 'AddCallerSkip'
+// as they share the dispatcher of the logger they were derived from
 func (l *Logger) Close() {
+	if !l.owner {
+		return
+	}
 	l.dispatcher.Close()
 }
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// struct 'collectingHandler' implements 'Handler' interface by keeping the handled records
+type collectingHandler struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+func (h *collectingHandler) Handle(_ context.Context, r Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r)
+	return nil
+}
+
+func (h *collectingHandler) messages() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	messages := make([]string, len(h.records))
+	for i, r := range h.records {
+		messages[i] = r.Message
+	}
+	return messages
+}
+
+func TestCloseOfDerivedChildIsNoop(t *testing.T) {
+	h := &collectingHandler{}
+	root := NewLogger(WithHandler(h))
+
+	root.With(String("k", "v")).Close()
+	root.Named("db").Close()
+	root.WithLevel(Debug).Close()
+
+	// the root dispatcher still runs, logging must neither panic nor be lost
+	root.Info("after child close")
+	root.Close()
+
+	if got := h.messages(); len(got) != 1 || got[0] != "after child close" {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestWithHandlersOwnsItsDispatcher(t *testing.T) {
+	rootHandler, childHandler := &collectingHandler{}, &collectingHandler{}
+	root := NewLogger(WithHandler(rootHandler))
+	child := root.WithHandlers(childHandler)
+
+	child.Info("to child")
+	child.With(String("k", "v")).Close()
+	child.Close()
+	root.Info("to root")
+	root.Close()
+
+	if got := childHandler.messages(); len(got) != 1 || got[0] != "to child" {
+		t.Errorf("child got %v", got)
+	}
+	if got := rootHandler.messages(); len(got) != 1 || got[0] != "to root" {
+		t.Errorf("root got %v", got)
+	}
+}
+
+func TestWithHandlersGetsWALOfItsOwn(t *testing.T) {
+	dir := t.TempDir()
+	root := NewLogger(WithHandler(&collectingHandler{}), WithWAL(WALConfig{Dir: dir}))
+	audit := root.Named("audit").WithHandlers(&collectingHandler{})
+	other := root.Named("audit").WithHandlers(&collectingHandler{})
+
+	for _, sub := range []string{"audit", "audit-1"} {
+		if _, err := os.Stat(filepath.Join(dir, "children", sub)); err != nil {
+			t.Errorf("missing write-ahead log of child: %v", err)
+		}
+	}
+	audit.Close()
+	other.Close()
+	root.Close()
+}
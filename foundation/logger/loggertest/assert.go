@@ -0,0 +1,168 @@
+package loggertest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+// struct 'Matcher' represents a condition on a record,
+// 'Description' is shown in failure messages
+type Matcher struct {
+	Description string
+	Match       func(r logger.Record) bool
+}
+
+// function 'Level' returns a matcher for records of the given level
+func Level(level logger.Level) Matcher {
+	return Matcher{
+		Description: "level " + level.String(),
+		Match:       func(r logger.Record) bool { return r.Level == level },
+	}
+}
+
+// function 'Message' returns a matcher for records with exactly the given message
+func Message(msg string) Matcher {
+	return Matcher{
+		Description: fmt.Sprintf("message %q", msg),
+		Match:       func(r logger.Record) bool { return r.Message == msg },
+	}
+}
+
+// function 'MessageContains' returns a matcher for records whose message contains the given text
+func MessageContains(text string) Matcher {
+	return Matcher{
+		Description: fmt.Sprintf("message containing %q", text),
+		Match:       func(r logger.Record) bool { return strings.Contains(r.Message, text) },
+	}
+}
+
+// function 'HasField' returns a matcher for records carrying a field with the given key
+func HasField(key string) Matcher {
+	return Matcher{
+		Description: fmt.Sprintf("field %q", key),
+		Match: func(r logger.Record) bool {
+			_, ok := fieldValue(r, key)
+			return ok
+		},
+	}
+}
+
+// function 'Field' returns a matcher for records carrying a field with the given key and value,
+// numbers of different types are equal when their values are, e.g. int 1 and float64 1,
+// and an error is equal to its message
+func Field(key string, value any) Matcher {
+	return Matcher{
+		Description: fmt.Sprintf("field %q = %v", key, value),
+		Match: func(r logger.Record) bool {
+			v, ok := fieldValue(r, key)
+			return ok && equal(v, value)
+		},
+	}
+}
+
+// function 'HasRecord' fails the test unless a record satisfies all matchers and returns the first one
+func HasRecord(t testing.TB, r *Recorder, matchers ...Matcher) logger.Record {
+	t.Helper()
+	if rec, ok := r.Find(matchers...); ok {
+		return rec
+	}
+	t.Errorf("no record with %s among %s", describe(matchers), summary(r.Records()))
+	return logger.Record{}
+}
+
+// function 'CountByLevel' fails the test unless exactly want records of the given level were recorded
+func CountByLevel(t testing.TB, r *Recorder, level logger.Level, want int) {
+	t.Helper()
+	if got := r.Count(Level(level)); got != want {
+		t.Errorf("got %d %s records, want %d among %s", got, level, want, summary(r.Records()))
+	}
+}
+
+// function 'NoErrors' fails the test when a record of the 'Error' level was recorded
+func NoErrors(t testing.TB, r *Recorder) {
+	t.Helper()
+	for _, rec := range r.Records() {
+		if rec.Level == logger.Error {
+			t.Errorf("unexpected error record %q with fields %v", rec.Message, rec.Fields)
+		}
+	}
+}
+
+// function 'matchAll' reports whether the record satisfies all matchers
+func matchAll(r logger.Record, matchers []Matcher) bool {
+	for _, m := range matchers {
+		if !m.Match(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// function 'fieldValue' returns the value of the field with the given key, the last one wins as in the formatters
+func fieldValue(r logger.Record, key string) (any, bool) {
+	for i := len(r.Fields) - 1; i >= 0; i-- {
+		if r.Fields[i].Key == key {
+			return r.Fields[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// function 'equal' compares a field value with an expected value
+func equal(got, want any) bool {
+	if reflect.DeepEqual(got, want) {
+		return true
+	}
+	if err, ok := got.(error); ok {
+		if s, ok := want.(string); ok {
+			return err.Error() == s
+		}
+	}
+	g, gok := number(got)
+	w, wok := number(want)
+	return gok && wok && g == w
+}
+
+// function 'number' converts a numeric value to float64
+func number(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// function 'describe' joins the descriptions of the matchers
+func describe(matchers []Matcher) string {
+	if len(matchers) == 0 {
+		return "any content"
+	}
+	parts := make([]string, len(matchers))
+	for i, m := range matchers {
+		parts[i] = m.Description
+	}
+	return strings.Join(parts, " and ")
+}
+
+// function 'summary' lists the level and message of the records for failure messages
+func summary(records []logger.Record) string {
+	if len(records) == 0 {
+		return "no records"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d records:", len(records))
+	for _, r := range records {
+		fmt.Fprintf(&b, "\n\t%s %q %v", r.Level, r.Message, r.Fields)
+	}
+	return b.String()
+}
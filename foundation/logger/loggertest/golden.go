@@ -0,0 +1,79 @@
+package loggertest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+// constant 'UpdateEnv' is the environment variable that rewrites golden files instead of comparing them,
+// e.g. "LOGGERTEST_UPDATE=1 go test ./..."
+const UpdateEnv = "LOGGERTEST_UPDATE"
+
+// variables for the patterns replaced by 'Normalize'
+var (
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+	callerPattern    = regexp.MustCompile(`[\w.@/\\-]*\.go:\d+`)
+)
+
+// function 'Normalize' replaces the parts of formatted records that change between runs,
+// RFC 3339 timestamps become "<timestamp>" and file:line locations of callers and stack frames become "<caller>"
+func Normalize(output []byte) []byte {
+	output = timestampPattern.ReplaceAll(output, []byte("<timestamp>"))
+	return callerPattern.ReplaceAll(output, []byte("<caller>"))
+}
+
+// function 'Golden' compares the normalized output with the golden file at the given path,
+// the file is written instead when the 'UpdateEnv' environment variable is set
+func Golden(t testing.TB, path string, output []byte) {
+	t.Helper()
+	got := Normalize(output)
+
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("golden file %s: %v", path, err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("golden file %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("golden file %s: %v, set %s=1 to create it", path, err, UpdateEnv)
+	}
+	if bytes.Equal(got, want) {
+		return
+	}
+
+	gotLines, wantLines := bytes.Split(got, []byte("\n")), bytes.Split(want, []byte("\n"))
+	for i := 0; i < len(gotLines) || i < len(wantLines); i++ {
+		var g, w []byte
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if !bytes.Equal(g, w) {
+			t.Errorf("output differs from golden file %s at line %d:\n\tgot:  %s\n\twant: %s", path, i+1, g, w)
+			return
+		}
+	}
+}
+
+// function 'GoldenRecords' formats the recorded records with the given formatter
+// and compares the result with the golden file at the given path, see 'Golden'
+func GoldenRecords(t testing.TB, r *Recorder, formatter logger.Formatter, path string) {
+	t.Helper()
+	var output []byte
+	for _, rec := range r.Records() {
+		output = append(output, formatter.Format(rec)...)
+	}
+	Golden(t, path, output)
+}
@@ -0,0 +1,61 @@
+package loggertest
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+func TestRecorderMatchers(t *testing.T) {
+	l, rec := NewTestLogger(t)
+	l.Info("user signed in", logger.String("user", "bob"), logger.Int("attempt", 2))
+	l.Warn("quota low", logger.Field{Key: "ratio", Value: 0.9})
+	l.Error("save failed", logger.Err(errors.New("disk full")))
+
+	HasRecord(t, rec, Message("user signed in"), Field("user", "bob"), Field("attempt", 2.0))
+	HasRecord(t, rec, MessageContains("quota"), HasField("ratio"), Level(logger.Warn))
+	HasRecord(t, rec, Level(logger.Error), Field("error", "disk full"))
+	CountByLevel(t, rec, logger.Info, 1)
+
+	if _, ok := rec.Find(Message("user signed in"), Field("user", "alice")); ok {
+		t.Error("Find matched a record with a different field value")
+	}
+	if n := rec.Count(Level(logger.Debug)); n != 0 {
+		t.Errorf("got %d debug records", n)
+	}
+
+	rec.Reset()
+	NoErrors(t, rec)
+	if len(rec.Records()) != 0 {
+		t.Errorf("Reset kept %d records", len(rec.Records()))
+	}
+}
+
+func TestNewTestLoggerCloseIsSafe(t *testing.T) {
+	l, rec := NewTestLogger(t)
+	l.Info("before close")
+	l.Close()
+	// the cleanup registered by 'NewTestLogger' closes the logger a second time
+	CountByLevel(t, rec, logger.Info, 1)
+}
+
+func TestNormalize(t *testing.T) {
+	in := `{"ts":"2024-01-02T03:04:05.678Z","caller":"service/orders.go:42"} at 2024-01-02 03:04:05+01:00 in /src/main.go:7`
+	want := `{"ts":"<timestamp>","caller":"<caller>"} at <timestamp> in <caller>`
+	if got := string(Normalize([]byte(in))); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestGoldenUpdatesAndCompares(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "output.golden")
+	output := []byte("time=2024-01-02T03:04:05Z msg=hello caller=main.go:7\n")
+
+	t.Setenv(UpdateEnv, "1")
+	Golden(t, path, output)
+	t.Setenv(UpdateEnv, "")
+	// the timestamp and caller differ but are normalized away
+	Golden(t, path, []byte("time=2025-06-07T08:09:10Z msg=hello caller=main.go:99\n"))
+}
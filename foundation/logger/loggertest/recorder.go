@@ -0,0 +1,100 @@
+package loggertest
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+// struct 'Recorder' implements 'Handler' interface,
+// it keeps every handled record in memory and is safe for concurrent use
+type Recorder struct {
+	mu      sync.Mutex
+	records []logger.Record
+}
+
+// function 'NewRecorder' creates a new empty 'Recorder'
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// function 'Handle' records the given record
+func (r *Recorder) Handle(ctx context.Context, rec logger.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, rec)
+	return nil
+}
+
+// function 'Records' returns a copy of the recorded records in the order they were handled
+func (r *Recorder) Records() []logger.Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	records := make([]logger.Record, len(r.records))
+	copy(records, r.records)
+	return records
+}
+
+// function 'Reset' removes all recorded records
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = nil
+}
+
+// function 'Find' returns the first record satisfying all matchers, it reports false when there is none
+func (r *Recorder) Find(matchers ...Matcher) (logger.Record, bool) {
+	for _, rec := range r.Records() {
+		if matchAll(rec, matchers) {
+			return rec, true
+		}
+	}
+	return logger.Record{}, false
+}
+
+// function 'Count' returns the number of records satisfying all matchers
+func (r *Recorder) Count(matchers ...Matcher) int {
+	n := 0
+	for _, rec := range r.Records() {
+		if matchAll(rec, matchers) {
+			n++
+		}
+	}
+	return n
+}
+
+// function 'NewTestLogger' creates a new logger recording every record and writing it to 't.Log',
+// records are delivered synchronously so they can be asserted right after the logging call,
+// the level is 'Debug' unless changed by the given options and the logger is closed when the test ends,
+// closing it in the test as well is safe
+func NewTestLogger(t testing.TB, opts ...logger.Option) (*logger.Logger, *Recorder) {
+	t.Helper()
+	recorder := NewRecorder()
+	options := []logger.Option{
+		logger.WithLevel(logger.Debug),
+		logger.WithSynchronous(),
+		logger.WithHandler(recorder),
+		logger.WithHandler(&testingHandler{t: t, formatter: &logger.DevFormatter{MessageWidth: 40}}),
+		logger.WithInternalErrorHandler(func(err error) {
+			t.Logf("logger [internal]: %v", err)
+		}),
+	}
+	l := logger.NewLogger(append(options, opts...)...)
+	t.Cleanup(l.Close)
+	return l, recorder
+}
+
+// struct 'testingHandler' implements 'Handler' interface by writing records to the log of a test
+type testingHandler struct {
+	t         testing.TB
+	formatter logger.Formatter
+}
+
+// function 'Handle' formats the given record and writes it to the log of the test
+func (h *testingHandler) Handle(ctx context.Context, r logger.Record) error {
+	h.t.Log(strings.TrimRight(string(h.formatter.Format(r)), "\n"))
+	return nil
+}
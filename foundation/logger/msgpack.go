@@ -0,0 +1,299 @@
+package logger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// constant 'msgpackTimestampExt' is the extension type of MessagePack timestamps
+const msgpackTimestampExt = -1
+
+// struct 'MsgPackFormatter' implements 'BinaryFormatter' interface,
+// it encodes records as MessagePack maps, timestamps use the timestamp extension type
+type MsgPackFormatter struct{}
+
+// function 'NewMsgPackFormatter' creates a new 'MsgPackFormatter'
+func NewMsgPackFormatter() *MsgPackFormatter {
+	return &MsgPackFormatter{}
+}
+
+// function 'Format' formats the given record as a length-delimited MessagePack frame
+func (f *MsgPackFormatter) Format(r Record) []byte {
+	return frame(msgpackAppend(nil, binaryRecord(r)))
+}
+
+// function 'Decode' decodes a MessagePack payload back into a record
+func (f *MsgPackFormatter) Decode(payload []byte) (Record, error) {
+	br := &byteReader{b: payload}
+	v, err := msgpackDecode(br)
+	if err != nil {
+		return Record{}, fmt.Errorf("msgpack: %w", err)
+	}
+	if err := br.done(); err != nil {
+		return Record{}, fmt.Errorf("msgpack: %w", err)
+	}
+	doc, ok := v.([]Field)
+	if !ok {
+		return Record{}, fmt.Errorf("msgpack: record is %T, not a map", v)
+	}
+	return recordFromBinary(doc)
+}
+
+// function 'msgpackLen' appends the header of a sized value using the smallest of the given formats,
+// fix is the fixed format prefix or zero when the type has none, fixMax is its largest length
+func msgpackLen(b []byte, n int, fix byte, fixMax int, f8, f16, f32 byte) []byte {
+	switch {
+	case fix != 0 && n <= fixMax:
+		return append(b, fix|byte(n))
+	case f8 != 0 && n <= math.MaxUint8:
+		return append(b, f8, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, f16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, f32), uint32(n))
+	}
+}
+
+// function 'msgpackAppend' appends the MessagePack encoding of a value
+func msgpackAppend(b []byte, v any) []byte {
+	switch val := normalize(v).(type) {
+	case nil:
+		return append(b, 0xc0)
+	case bool:
+		if val {
+			return append(b, 0xc3)
+		}
+		return append(b, 0xc2)
+	case int64:
+		switch {
+		case val >= 0:
+			return msgpackAppend(b, uint64(val))
+		case val >= -32:
+			return append(b, byte(val))
+		case val >= math.MinInt8:
+			return append(b, 0xd0, byte(val))
+		case val >= math.MinInt16:
+			return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(val))
+		case val >= math.MinInt32:
+			return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(val))
+		default:
+			return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(val))
+		}
+	case uint64:
+		switch {
+		case val <= 0x7f:
+			return append(b, byte(val))
+		case val <= math.MaxUint8:
+			return append(b, 0xcc, byte(val))
+		case val <= math.MaxUint16:
+			return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(val))
+		case val <= math.MaxUint32:
+			return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(val))
+		default:
+			return binary.BigEndian.AppendUint64(append(b, 0xcf), val)
+		}
+	case float64:
+		return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(val))
+	case string:
+		return append(msgpackLen(b, len(val), 0xa0, 31, 0xd9, 0xda, 0xdb), val...)
+	case []byte:
+		return append(msgpackLen(b, len(val), 0, 0, 0xc4, 0xc5, 0xc6), val...)
+	case time.Time:
+		// timestamp 96: a 32 bit nanoseconds field followed by 64 bit seconds
+		b = append(b, 0xc7, 12, byte(msgpackTimestampExt&0xff))
+		b = binary.BigEndian.AppendUint32(b, uint32(val.Nanosecond()))
+		return binary.BigEndian.AppendUint64(b, uint64(val.Unix()))
+	case []any:
+		b = msgpackLen(b, len(val), 0x90, 15, 0, 0xdc, 0xdd)
+		for _, item := range val {
+			b = msgpackAppend(b, item)
+		}
+		return b
+	case []Field:
+		b = msgpackLen(b, len(val), 0x80, 15, 0, 0xde, 0xdf)
+		for _, field := range val {
+			b = msgpackAppend(b, field.Key)
+			b = msgpackAppend(b, field.Value)
+		}
+		return b
+	default:
+		return msgpackAppend(b, fmt.Sprintf("%v", val))
+	}
+}
+
+// function 'msgpackDecode' decodes the next value, maps are decoded as '[]Field' to keep their order
+func msgpackDecode(br *byteReader) (any, error) {
+	if err := br.enter(); err != nil {
+		return nil, err
+	}
+	defer br.leave()
+
+	c, err := br.byte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return msgpackDecodeMap(br, uint64(c&0x0f))
+	case c&0xf0 == 0x90:
+		return msgpackDecodeArray(br, uint64(c&0x0f))
+	case c&0xe0 == 0xa0:
+		return msgpackDecodeString(br, uint64(c&0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := br.uint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b, err := br.next(n)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case 0xc7, 0xc8, 0xc9:
+		n, err := br.uint(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return msgpackDecodeExt(br, n)
+	case 0xca:
+		bits, err := br.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(uint32(bits))), nil
+	case 0xcb:
+		bits, err := br.uint(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(bits), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := br.uint(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return decodedInt(n), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		n, err := br.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		// sign extend from the encoded size
+		shift := 64 - 8*size
+		return int64(n<<shift) >> shift, nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return msgpackDecodeExt(br, 1<<(c-0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := br.uint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return msgpackDecodeString(br, n)
+	case 0xdc, 0xdd:
+		n, err := br.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return msgpackDecodeArray(br, n)
+	case 0xde, 0xdf:
+		n, err := br.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return msgpackDecodeMap(br, n)
+	default:
+		return nil, fmt.Errorf("unsupported format byte 0x%02x", c)
+	}
+}
+
+// function 'msgpackDecodeString' decodes a string of n bytes
+func msgpackDecodeString(br *byteReader, n uint64) (any, error) {
+	b, err := br.next(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// function 'msgpackDecodeArray' decodes an array of n items
+func msgpackDecodeArray(br *byteReader, n uint64) (any, error) {
+	if n > uint64(len(br.b)) {
+		return nil, errShortPayload
+	}
+	items := make([]any, 0, n)
+	for i := uint64(0); i < n; i++ {
+		item, err := msgpackDecode(br)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// function 'msgpackDecodeMap' decodes a map of n entries
+func msgpackDecodeMap(br *byteReader, n uint64) (any, error) {
+	if n > uint64(len(br.b)) {
+		return nil, errShortPayload
+	}
+	fields := make([]Field, 0, n)
+	for i := uint64(0); i < n; i++ {
+		key, err := msgpackDecode(br)
+		if err != nil {
+			return nil, err
+		}
+		value, err := msgpackDecode(br)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, Field{Key: fmt.Sprint(key), Value: value})
+	}
+	return fields, nil
+}
+
+// function 'msgpackDecodeExt' decodes an extension value with n bytes of data,
+// timestamps are decoded as 'time.Time' and other extensions as their raw data
+func msgpackDecodeExt(br *byteReader, n uint64) (any, error) {
+	typ, err := br.byte()
+	if err != nil {
+		return nil, err
+	}
+	data, err := br.next(n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(typ) != msgpackTimestampExt {
+		return append([]byte(nil), data...), nil
+	}
+
+	switch n {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(data)), 0).UTC(), nil
+	case 8:
+		v := binary.BigEndian.Uint64(data)
+		return time.Unix(int64(v&0x3ffffffff), int64(v>>34)).UTC(), nil
+	case 12:
+		nsec := binary.BigEndian.Uint32(data)
+		sec := int64(binary.BigEndian.Uint64(data[4:]))
+		return time.Unix(sec, int64(nsec)).UTC(), nil
+	default:
+		return nil, fmt.Errorf("invalid timestamp of %d bytes", n)
+	}
+}
@@ -1,6 +1,10 @@
 package logger
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"os"
+)
 
 type Option func(*Logger)
 
@@ -35,13 +39,24 @@ func WithHook(h Hook) Option {
 // function 'WithContext' returns an option to set the context for the logger
 func WithContext(ctx context.Context) Option {
 	return func(l *Logger) {
-		l.ctx = ctx
+		l.config.ctx = ctx
 	}
 }
 
-// function 'WithBuildInfo' returns an option to add build information to the logger
+// function 'WithBuildInfo' returns an option to add build information to the logger,
+// environment variables that are not set are reported to the internal error handler
 func WithBuildInfo(versionKey, commitKey, timeKey string, once bool) Option {
 	return func(l *Logger) {
+		missing := map[string]bool{}
+		for _, key := range []string{versionKey, commitKey, timeKey} {
+			if os.Getenv(key) == "" {
+				missing[key] = true
+			}
+		}
+		if err := missingEnvError(missing); err != nil {
+			l.config.errs = append(l.config.errs, fmt.Errorf("incomplete build information: %w", err))
+		}
+
 		info := NewBuildInfo(versionKey, commitKey, timeKey)
 		if once {
 			l.onceBuildInfo = info.Fields()
@@ -76,10 +91,16 @@ func WithService(service string) Option {
 
 // function 'WithServiceEnv' returns an option to add service name to the logger as a field,
 // the service name is retrieved from the environment variable with the given key,
-// if the environment variable is not set, it returns the key name itself
+// if the environment variable is not set, the field is omitted and the missing variable is reported
+// to the internal error handler
 func WithServiceEnv(service string) Option {
 	return func(l *Logger) {
-		l.Fields = append(l.Fields, String("service", getEnvOrKey(service)))
+		val, err := lookupEnv(service)
+		if err != nil {
+			l.config.errs = append(l.config.errs, fmt.Errorf("omitting service field: %w", err))
+			return
+		}
+		l.Fields = append(l.Fields, String("service", val))
 	}
 }
 
@@ -92,10 +113,16 @@ func WithEnvironment(environment string) Option {
 
 // function 'WithEnvironmentEnv' returns an option to add environment name to the logger as a field,
 // the environment name is retrieved from the environment variable with the given key,
-// if the environment variable is not set, it returns the key name itself
+// if the environment variable is not set, the field is omitted and the missing variable is reported
+// to the internal error handler
 func WithEnvironmentEnv(environment string) Option {
 	return func(l *Logger) {
-		l.Fields = append(l.Fields, String("environment", getEnvOrKey(environment)))
+		val, err := lookupEnv(environment)
+		if err != nil {
+			l.config.errs = append(l.config.errs, fmt.Errorf("omitting environment field: %w", err))
+			return
+		}
+		l.Fields = append(l.Fields, String("environment", val))
 	}
 }
 
@@ -103,7 +130,7 @@ func WithEnvironmentEnv(environment string) Option {
 // the traceId key is used to retrieve the traceId value from the context
 func WithTraceIdKey(traceIdKey string) Option {
 	return func(l *Logger) {
-		l.traceIdKey = traceIdKey
+		l.config.traceIdKey = traceIdKey
 	}
 }
 
@@ -111,7 +138,7 @@ func WithTraceIdKey(traceIdKey string) Option {
 // the buffer size is used to limit the number of log records that can be buffered
 func WithBufferSize(size int) Option {
 	return func(l *Logger) {
-		l.bufferSize = size
+		l.config.bufferSize = size
 	}
 }
 
@@ -119,7 +146,7 @@ func WithBufferSize(size int) Option {
 // the backpressure strategy is used to control the behavior of the logger when the buffer is full
 func WithBackpressure(strategy BackpressureStrategy) Option {
 	return func(l *Logger) {
-		l.backpressure = strategy
+		l.config.backpressure = strategy
 	}
 }
 
@@ -127,14 +154,96 @@ func WithBackpressure(strategy BackpressureStrategy) Option {
 // the number of workers is used to control the number of worker goroutines that process log records
 func WithWorkers(n int) Option {
 	return func(l *Logger) {
-		l.numWorkers = n
+		l.config.numWorkers = n
+	}
+}
+
+// function 'WithSynchronous' returns an option to deliver records on the goroutine logging them,
+// buffer size, backpressure and workers are ignored, see 'NewSyncDispatcher'
+func WithSynchronous() Option {
+	return func(l *Logger) {
+		l.config.synchronous = true
 	}
 }
 
 // function 'WithInternalErrorHandler' returns an option to set the internal error handler for the logger,
-// the internal error handler is used to handle errors that occur within the logger
+// the internal error handler is used to handle errors that occur within the logger,
+// handler failures are passed as '*HandlerError' carrying the handler and the failed record
 func WithInternalErrorHandler(f func(error)) Option {
 	return func(l *Logger) {
-		l.internalErrorHandler = f
+		l.config.internalErrorHandler = f
+	}
+}
+
+// function 'WithClock' returns an option to set the clock used to timestamp records,
+// a fixed clock makes the output of the logger reproducible in golden tests
+func WithClock(c Clock) Option {
+	return func(l *Logger) {
+		if c != nil {
+			l.config.clock = c
+		}
+	}
+}
+
+// function 'WithCallerSkip' returns an option to skip additional frames when reporting the caller,
+// it is used when the logger is always called through a helper
+func WithCallerSkip(skip int) Option {
+	return func(l *Logger) {
+		l.callerSkip = skip
+	}
+}
+
+// function 'WithCallerFormat' returns an option to set how the caller is reported,
+// 'CallerNone' turns caller capture off entirely
+func WithCallerFormat(format CallerFormat) Option {
+	return func(l *Logger) {
+		l.config.callerFormat = format
+	}
+}
+
+// function 'WithStacktraceLevel' returns an option to capture the goroutine stack for records
+// at or above the given level, the stack starts at the caller of the logger
+func WithStacktraceLevel(level Level) Option {
+	return func(l *Logger) {
+		l.config.stacktrace = true
+		l.config.stacktraceLevel = level
+	}
+}
+
+// function 'WithStacktraceDepth' returns an option to limit the number of captured stack frames
+func WithStacktraceDepth(depth int) Option {
+	return func(l *Logger) {
+		l.config.stacktraceDepth = depth
+	}
+}
+
+// function 'WithLevelOverrides' returns an option to set minimum logging levels per logger name,
+// e.g. "auth.db.*=debug,http=warn,*=info", see 'LevelOverrides',
+// an invalid specification is reported to the internal error handler and ignored
+func WithLevelOverrides(spec string) Option {
+	return func(l *Logger) {
+		if err := l.SetLevelOverrides(spec); err != nil {
+			l.config.errs = append(l.config.errs, fmt.Errorf("ignoring level overrides: %w", err))
+		}
+	}
+}
+
+// function 'WithLevelOverridesEnv' returns an option to set minimum logging levels per logger name,
+// the specification is retrieved from the environment variable with the given key,
+// if the environment variable is not set, no overrides are set
+func WithLevelOverridesEnv(key string) Option {
+	return func(l *Logger) {
+		if spec, ok := os.LookupEnv(key); ok {
+			WithLevelOverrides(spec)(l)
+		}
+	}
+}
+
+// function 'WithWAL' returns an option to append every record to a write-ahead log on disk before it is queued,
+// records not delivered when the process stops are delivered on the next start, see 'Dispatcher.OpenWAL',
+// loggers created by 'Logger.WithHandlers' get a write-ahead log of their own below the directory
+func WithWAL(c WALConfig) Option {
+	return func(l *Logger) {
+		l.config.wal = &c
 	}
 }
@@ -1,13 +1,69 @@
 package logger
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
 
-// function 'getEnvOrKey' returns the value of the environment variable with the given key,
-// if the environment variable is not set, it returns the key name itself
-func getEnvOrKey(key string) string {
+// function 'lookupEnv' returns the value of the environment variable with the given key,
+// if the environment variable is not set or empty, it returns an error naming the variable
+func lookupEnv(key string) (string, error) {
 	val := os.Getenv(key)
 	if val == "" {
-		return "${" + key + "}"
+		return "", fmt.Errorf("environment variable %q is not set", key)
 	}
-	return val
+	return val, nil
+}
+
+// function 'expandEnv' replaces "${KEY}" and "${KEY:-default}" references with the values of
+// the environment variables, the names of referenced variables that are not set are added to missing,
+// a bare "$KEY" and an unterminated "${" are kept as they are so text templates such as "{{ $level }}" survive
+func expandEnv(s string, missing map[string]bool) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(s[start+2:], '}')
+		if end < 0 {
+			break
+		}
+		b.WriteString(s[:start])
+		b.WriteString(envRef(s[start+2:start+2+end], missing))
+		s = s[start+2+end+1:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// function 'envRef' returns the value of a "KEY" or "KEY:-default" reference
+func envRef(ref string, missing map[string]bool) string {
+	key, fallback, hasFallback := strings.Cut(ref, ":-")
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	if hasFallback {
+		return fallback
+	}
+	missing[key] = true
+	return ""
+}
+
+// function 'missingEnvError' returns an error listing the missing environment variables, nil when none are missing
+func missingEnvError(missing map[string]bool) error {
+	if len(missing) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(missing))
+	for key := range missing {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return fmt.Errorf("missing required environment variables: %s", strings.Join(keys, ", "))
 }
@@ -0,0 +1,31 @@
+package logger
+
+import "testing"
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("LOG_TEST_LEVEL", "debug")
+
+	for in, want := range map[string]string{
+		"${LOG_TEST_LEVEL}":                   "debug",
+		"level=${LOG_TEST_LEVEL}!":            "level=debug!",
+		"${LOG_TEST_UNSET:-info}":             "info",
+		"${LOG_TEST_LEVEL}/${LOG_TEST_LEVEL}": "debug/debug",
+		"{{ $level := .Level }}{{ $level }}":  "{{ $level := .Level }}{{ $level }}",
+		"$LOG_TEST_LEVEL and $$":              "$LOG_TEST_LEVEL and $$",
+		"unterminated ${LOG_TEST_LEVEL":       "unterminated ${LOG_TEST_LEVEL",
+		"{{ $x }} ${LOG_TEST_LEVEL} {{ $y }}": "{{ $x }} debug {{ $y }}",
+	} {
+		missing := map[string]bool{}
+		if got := expandEnv(in, missing); got != want {
+			t.Errorf("expandEnv(%q) = %q, want %q", in, got, want)
+		}
+		if len(missing) > 0 {
+			t.Errorf("expandEnv(%q) reported missing %v", in, missing)
+		}
+	}
+
+	missing := map[string]bool{}
+	if got := expandEnv("${LOG_TEST_UNSET}", missing); got != "" || !missing["LOG_TEST_UNSET"] {
+		t.Errorf("got %q, missing %v", got, missing)
+	}
+}
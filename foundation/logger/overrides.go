@@ -0,0 +1,190 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// struct 'LevelOverrides' represents minimum logging levels set per logger name,
+// patterns are matched against the dotted names given by 'Logger.Named':
+//   - "auth.db" matches the logger "auth.db" and all of its descendants
+//   - "auth.db.*" matches the descendants of "auth.db" only
+//   - "*" matches every logger, including the root logger
+//
+// the most specific pattern matching a name wins, e.g. "auth.db.*=debug,http=warn,*=info"
+type LevelOverrides struct {
+	spec string
+	root *levelNode
+}
+
+// struct 'levelNode' represents a name segment in the trie of 'LevelOverrides',
+// 'self' applies to the name ending at the node and 'below' to the names under it
+type levelNode struct {
+	children map[string]*levelNode
+	self     *Level
+	below    *Level
+}
+
+// struct 'levelCache' holds the level resolved for a logger name,
+// it is valid as long as the overrides it was resolved from are in effect
+type levelCache struct {
+	p atomic.Pointer[cachedLevel]
+}
+
+// struct 'cachedLevel' represents a level resolved from a set of overrides
+type cachedLevel struct {
+	overrides *LevelOverrides
+	level     Level
+	ok        bool
+}
+
+// function 'ParseLevelOverrides' parses comma separated "pattern=level" pairs,
+// a level without pattern is a shorthand for "*=level" and empty pairs are ignored
+func ParseLevelOverrides(spec string) (*LevelOverrides, error) {
+	o := &LevelOverrides{spec: spec, root: &levelNode{}}
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		pattern, name, found := strings.Cut(pair, "=")
+		if !found {
+			pattern, name = "*", pair
+		}
+		pattern = strings.TrimSpace(pattern)
+		level, err := ParseLevel(strings.TrimSpace(name))
+		if err != nil {
+			return nil, fmt.Errorf("level override %q: %w", pair, err)
+		}
+		if err := o.add(pattern, level); err != nil {
+			return nil, fmt.Errorf("level override %q: %w", pair, err)
+		}
+	}
+	return o, nil
+}
+
+// function 'add' adds a pattern to the trie
+func (o *LevelOverrides) add(pattern string, level Level) error {
+	if pattern == "*" {
+		o.root.self = &level
+		o.root.below = &level
+		return nil
+	}
+
+	descendants := false
+	if rest, ok := strings.CutSuffix(pattern, ".*"); ok {
+		pattern, descendants = rest, true
+	}
+	node := o.root
+	for _, segment := range strings.Split(pattern, ".") {
+		if segment == "" || strings.Contains(segment, "*") {
+			return fmt.Errorf("invalid pattern %q, expected a dotted name optionally ending with \".*\"", pattern)
+		}
+		next, ok := node.children[segment]
+		if !ok {
+			if node.children == nil {
+				node.children = map[string]*levelNode{}
+			}
+			next = &levelNode{}
+			node.children[segment] = next
+		}
+		node = next
+	}
+
+	if !descendants {
+		node.self = &level
+	}
+	node.below = &level
+	return nil
+}
+
+// function 'Lookup' returns the level set for the given logger name,
+// it reports false when no pattern matches the name
+func (o *LevelOverrides) Lookup(name string) (Level, bool) {
+	node := o.root
+	best := node.below
+	if name == "" {
+		best = node.self
+	}
+
+	for name != "" {
+		segment, rest, more := strings.Cut(name, ".")
+		next, ok := node.children[segment]
+		if !ok {
+			break
+		}
+		node, name = next, rest
+		if !more {
+			if node.self != nil {
+				best = node.self
+			}
+			break
+		}
+		if node.below != nil {
+			best = node.below
+		}
+	}
+
+	if best == nil {
+		return 0, false
+	}
+	return *best, true
+}
+
+// function 'String' returns the specification the overrides were parsed from
+func (o *LevelOverrides) String() string {
+	return o.spec
+}
+
+// function 'SetLevelOverrides' replaces the level overrides of the logger and all loggers sharing its configuration,
+// an empty specification removes the overrides, an invalid one is rejected and the current overrides are kept
+func (l *Logger) SetLevelOverrides(spec string) error {
+	if strings.TrimSpace(spec) == "" {
+		l.config.levels.Store(nil)
+		return nil
+	}
+	o, err := ParseLevelOverrides(spec)
+	if err != nil {
+		return err
+	}
+	l.config.levels.Store(o)
+	return nil
+}
+
+// function 'LevelOverrides' returns the level overrides in effect, nil when none are set
+func (l *Logger) LevelOverrides() *LevelOverrides {
+	return l.config.levels.Load()
+}
+
+// function 'Enabled' reports whether a record of the given level would be logged,
+// level overrides matching the name of the logger take precedence over the level of the logger,
+// 'Audit' records are always enabled
+func (l *Logger) Enabled(level Level) bool {
+	if level == Audit {
+		return true
+	}
+	o := l.config.levels.Load()
+	if o == nil {
+		return level >= l.minLevel()
+	}
+
+	if l.levels != nil {
+		if c := l.levels.p.Load(); c != nil && c.overrides == o {
+			if c.ok {
+				return level >= c.level
+			}
+			return level >= l.minLevel()
+		}
+	}
+
+	resolved, ok := o.Lookup(l.name)
+	if l.levels != nil {
+		l.levels.p.Store(&cachedLevel{overrides: o, level: resolved, ok: ok})
+	}
+	if ok {
+		return level >= resolved
+	}
+	return level >= l.minLevel()
+}
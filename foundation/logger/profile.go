@@ -0,0 +1,312 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// type 'JSONProfile' represents a mapping of a record to the JSON document expected by a logging platform
+type JSONProfile func(r Record) map[string]any
+
+// constant 'ecsVersion' is the version of the Elastic Common Schema produced by 'ECSProfile'
+const ecsVersion = "8.11.0"
+
+// constant 'collidingFieldsKey' is the key user fields colliding with keys reserved by a profile are nested under
+const collidingFieldsKey = "fields"
+
+// variable 'ecsReservedKeys' are the top level keys written by 'ECSProfile',
+// user fields with these keys are nested under "fields" so they cannot overwrite them
+var ecsReservedKeys = map[string]bool{
+	"@timestamp": true, "message": true, "ecs": true, "log": true, "trace": true,
+	"span": true, "service": true, "error": true, collidingFieldsKey: true,
+}
+
+// variable 'gcpReservedKeys' are the top level keys written by 'GCPProfile',
+// user fields with these keys or the "logging.googleapis.com/" prefix are nested under "fields"
+var gcpReservedKeys = map[string]bool{
+	"severity": true, "message": true, "time": true, "stack_trace": true,
+	"serviceContext": true, collidingFieldsKey: true,
+}
+
+// variable 'gelfKeyPattern' matches the additional field names allowed by GELF
+var gelfKeyPattern = regexp.MustCompile(`[^\w.\-]`)
+
+// function 'DefaultProfile' returns the profile of the plain JSON format,
+// fields are written at the top level next to level, message, caller and timestamp
+func DefaultProfile() JSONProfile {
+	return TimestampProfile(TimestampEncoder{})
+}
+
+// function 'TimestampProfile' returns the profile of the plain JSON format
+// with the timestamp written by the given encoder
+func TimestampProfile(enc TimestampEncoder) JSONProfile {
+	return func(r Record) map[string]any {
+		payload := make(map[string]any, len(r.Fields)+4)
+		payload["level"] = r.Level.String()
+		payload["message"] = r.Message
+		payload["caller"] = r.Caller
+		payload[enc.key("timestamp")] = enc.Encode(r.Timestamp)
+		if len(r.Stack) > 0 {
+			payload["stacktrace"] = StackString(r.Stack)
+		}
+
+		for _, field := range r.Fields {
+			payload[field.Key] = jsonValue(field.Value)
+		}
+		return payload
+	}
+}
+
+// function 'ECSProfile' returns the profile of the Elastic Common Schema,
+// the well-known fields set by 'WithService', 'WithEnvironment' and 'BuildInfo' are mapped to
+// their ECS counterparts, an 'Err' field named "error" populates the ECS error fields,
+// other fields are written at the top level unless they collide with the keys above
+func ECSProfile() JSONProfile {
+	return func(r Record) map[string]any {
+		doc := map[string]any{
+			"@timestamp": r.Timestamp.UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+			"message":    r.Message,
+		}
+		setPath(doc, "ecs.version", ecsVersion)
+		setPath(doc, "log.level", r.Level.String())
+		if file, line, ok := splitCaller(r.Caller); ok {
+			setPath(doc, "log.origin.file.name", file)
+			setPath(doc, "log.origin.file.line", line)
+		}
+		if traceId := r.KnownTraceId(); traceId != "" {
+			setPath(doc, "trace.id", traceId)
+		}
+		if len(r.Stack) > 0 {
+			setPath(doc, "error.stack_trace", StackString(r.Stack))
+		}
+
+		for _, field := range r.Fields {
+			switch field.Key {
+			case "service":
+				setPath(doc, "service.name", field.Value)
+			case "environment":
+				setPath(doc, "service.environment", field.Value)
+			case "build_version":
+				setPath(doc, "service.version", field.Value)
+			case "span_id":
+				setPath(doc, "span.id", field.Value)
+			case "error":
+				if err, ok := field.Value.(ErrorValue); ok {
+					setECSError(doc, err)
+					continue
+				}
+				setField(doc, field, ecsReservedKeys[field.Key])
+			default:
+				setField(doc, field, ecsReservedKeys[field.Key])
+			}
+		}
+		return doc
+	}
+}
+
+// function 'GELFProfile' returns the profile of the Graylog Extended Log Format 1.1,
+// the host defaults to the name reported by the kernel, additional fields are prefixed with '_'
+// and nested values are flattened into dotted names because GELF only allows strings and numbers
+func GELFProfile(host string) JSONProfile {
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+	return func(r Record) map[string]any {
+		doc := map[string]any{
+			"version":       "1.1",
+			"host":          host,
+			"short_message": r.Message,
+			"timestamp":     float64(r.Timestamp.UnixMicro()) / 1e6,
+			"level":         r.Level.SyslogSeverity(),
+		}
+		if r.Caller != "" {
+			doc["_caller"] = r.Caller
+		}
+		if traceId := r.KnownTraceId(); traceId != "" {
+			doc["_trace_id"] = traceId
+		}
+		if len(r.Stack) > 0 {
+			doc["full_message"] = r.Message + "\n" + StackString(r.Stack)
+		}
+		for _, field := range r.Fields {
+			addGELFField(doc, field.Key, field.Value)
+		}
+		return doc
+	}
+}
+
+// function 'GCPProfile' returns the profile of Google Cloud Logging structured logs,
+// the trace id is linked to Cloud Trace when a project id is given,
+// the service and build version populate the service context used by Error Reporting,
+// fields colliding with the keys interpreted by Cloud Logging are nested under "fields"
+func GCPProfile(projectId string) JSONProfile {
+	return func(r Record) map[string]any {
+		doc := map[string]any{
+			"severity": gcpSeverity(r.Level),
+			"message":  r.Message,
+			"time":     r.Timestamp.Format(time.RFC3339Nano),
+		}
+		if file, line, ok := splitCaller(r.Caller); ok {
+			doc["logging.googleapis.com/sourceLocation"] = map[string]any{
+				"file": file,
+				"line": strconv.Itoa(line),
+			}
+		}
+		if traceId := r.KnownTraceId(); traceId != "" {
+			if projectId != "" {
+				traceId = "projects/" + projectId + "/traces/" + traceId
+			}
+			doc["logging.googleapis.com/trace"] = traceId
+		}
+		if len(r.Stack) > 0 {
+			doc["stack_trace"] = StackString(r.Stack)
+		}
+
+		serviceContext := map[string]any{}
+		for _, field := range r.Fields {
+			switch field.Key {
+			case "service":
+				serviceContext["service"] = field.Value
+			case "build_version":
+				serviceContext["version"] = field.Value
+			case "span_id":
+				doc["logging.googleapis.com/spanId"] = field.Value
+			default:
+				setField(doc, field, gcpReservedKeys[field.Key] || strings.HasPrefix(field.Key, "logging.googleapis.com/"))
+			}
+		}
+		if len(serviceContext) > 0 {
+			doc["serviceContext"] = serviceContext
+		}
+		return doc
+	}
+}
+
+// function 'setField' writes a user field at the top level of a document,
+// or under "fields" when it collides with a reserved key
+func setField(doc map[string]any, field Field, reserved bool) {
+	if !reserved {
+		doc[field.Key] = jsonValue(field.Value)
+		return
+	}
+	fields, ok := doc[collidingFieldsKey].(map[string]any)
+	if !ok {
+		fields = map[string]any{}
+		doc[collidingFieldsKey] = fields
+	}
+	fields[field.Key] = jsonValue(field.Value)
+}
+
+// function 'setECSError' maps an error to the ECS error fields,
+// the stack of the record takes precedence over the stack carried by the error
+func setECSError(doc map[string]any, err ErrorValue) {
+	obj := err.Object()
+	setPath(doc, "error.message", obj["message"])
+	setPath(doc, "error.type", obj["type"])
+	if stack, ok := obj["stacktrace"]; ok {
+		if e, ok := doc["error"].(map[string]any); ok && e["stack_trace"] == nil {
+			e["stack_trace"] = stack
+		}
+	}
+	if causes, ok := obj["causes"]; ok {
+		setPath(doc, "error.causes", causes)
+	}
+}
+
+// function 'addGELFField' adds an additional GELF field, nested values are flattened
+func addGELFField(doc map[string]any, key string, value any) {
+	switch val := value.(type) {
+	case []Field:
+		for _, field := range val {
+			addGELFField(doc, key+"."+field.Key, field.Value)
+		}
+		return
+	case map[string]any:
+		for k, v := range val {
+			addGELFField(doc, key+"."+k, v)
+		}
+		return
+	}
+
+	name := "_" + gelfKeyPattern.ReplaceAllString(key, "_")
+	if name == "_id" {
+		// '_id' is reserved by GELF
+		name = "__id"
+	}
+
+	switch val := value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		doc[name] = val
+	case string:
+		doc[name] = val
+	case nil:
+		doc[name] = ""
+	default:
+		doc[name] = fmt.Sprintf("%v", val)
+	}
+}
+
+// function 'setPath' sets a value in a nested document using a dotted path
+func setPath(doc map[string]any, path string, value any) {
+	keys := strings.Split(path, ".")
+	m := doc
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := m[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			m[key] = next
+		}
+		m = next
+	}
+	m[keys[len(keys)-1]] = value
+}
+
+// function 'jsonValue' converts a field value into a value encoded naturally by encoding/json,
+// groups become objects, 'Err' fields become error objects and other errors become their message
+func jsonValue(v any) any {
+	switch val := v.(type) {
+	case []Field:
+		return GroupMap(val)
+	case ErrorValue:
+		return val.Object()
+	case error:
+		return val.Error()
+	default:
+		return v
+	}
+}
+
+// function 'splitCaller' splits a "file:line" caller into its parts
+func splitCaller(caller string) (string, int, bool) {
+	i := strings.LastIndexByte(caller, ':')
+	if i < 0 {
+		return "", 0, false
+	}
+	line, err := strconv.Atoi(caller[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return caller[:i], line, true
+}
+
+// function 'gcpSeverity' maps a logging level to a Cloud Logging severity
+func gcpSeverity(level Level) string {
+	switch level {
+	case Debug:
+		return "DEBUG"
+	case Info:
+		return "INFO"
+	case Warn:
+		return "WARNING"
+	case Error:
+		return "ERROR"
+	case Audit:
+		return "NOTICE"
+	default:
+		return "DEFAULT"
+	}
+}
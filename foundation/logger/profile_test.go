@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestECSProfileKeepsReservedKeys(t *testing.T) {
+	r := Record{
+		Level:     Error,
+		Message:   "real message",
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Caller:    "main.go:7",
+		Fields: []Field{
+			String("message", "user message"),
+			String("log", "user log"),
+			String("@timestamp", "yesterday"),
+			String("service", "api"),
+			Err(fmt.Errorf("save: %w", errors.New("disk full"))),
+			String("user", "bob"),
+		},
+	}
+	doc := ECSProfile()(r)
+
+	if doc["message"] != "real message" || doc["@timestamp"] != "2024-01-02T03:04:05.000Z" {
+		t.Errorf("reserved keys were overwritten: %v", doc)
+	}
+	if log, ok := doc["log"].(map[string]any); !ok || log["level"] != "error" {
+		t.Errorf("got log %v", doc["log"])
+	}
+	fields, _ := doc["fields"].(map[string]any)
+	if fields["message"] != "user message" || fields["log"] != "user log" || fields["@timestamp"] != "yesterday" {
+		t.Errorf("colliding fields were not nested: %v", fields)
+	}
+	if service, _ := doc["service"].(map[string]any); service["name"] != "api" {
+		t.Errorf("got service %v", doc["service"])
+	}
+	if e, _ := doc["error"].(map[string]any); e["message"] != "save: disk full" || e["type"] != "*fmt.wrapError" {
+		t.Errorf("got error %v", doc["error"])
+	}
+	if doc["user"] != "bob" {
+		t.Errorf("got user %v", doc["user"])
+	}
+}
+
+func TestGCPProfileKeepsReservedKeys(t *testing.T) {
+	r := Record{
+		Level:   Warn,
+		Message: "real message",
+		Fields: []Field{
+			String("severity", "DEBUG"),
+			String("message", "user message"),
+			String("logging.googleapis.com/trace", "fake"),
+			String("service", "api"),
+			Int("attempt", 2),
+		},
+	}
+	doc := GCPProfile("project")(r)
+
+	if doc["severity"] != "WARNING" || doc["message"] != "real message" {
+		t.Errorf("reserved keys were overwritten: %v", doc)
+	}
+	if _, ok := doc["logging.googleapis.com/trace"]; ok {
+		t.Errorf("user field set the trace: %v", doc)
+	}
+	fields, _ := doc["fields"].(map[string]any)
+	if fields["severity"] != "DEBUG" || fields["message"] != "user message" || fields["logging.googleapis.com/trace"] != "fake" {
+		t.Errorf("colliding fields were not nested: %v", fields)
+	}
+	if ctx, _ := doc["serviceContext"].(map[string]any); ctx["service"] != "api" {
+		t.Errorf("got service context %v", doc["serviceContext"])
+	}
+	if doc["attempt"] != 2 {
+		t.Errorf("got attempt %v", doc["attempt"])
+	}
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// struct 'errorLimiter' limits how many internal errors are reported per interval
+type errorLimiter struct {
+	mu          sync.Mutex
+	limit       int
+	interval    time.Duration
+	windowStart time.Time
+	count       int
+	suppressed  int
+}
+
+// function 'newErrorLimiter' creates a new limiter allowing limit errors per interval
+func newErrorLimiter(limit int, interval time.Duration) *errorLimiter {
+	return &errorLimiter{limit: limit, interval: interval}
+}
+
+// function 'allow' reports whether an error may be reported now,
+// it also returns the number of errors suppressed since the last allowed one
+func (l *errorLimiter) allow() (bool, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.windowStart) >= l.interval {
+		l.windowStart = now
+		l.count = 0
+	}
+
+	if l.count >= l.limit {
+		l.suppressed++
+		return false, 0
+	}
+
+	l.count++
+	suppressed := l.suppressed
+	l.suppressed = 0
+	return true, suppressed
+}
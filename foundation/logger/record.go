@@ -10,4 +10,14 @@ type Record struct {
 	Caller    string
 	Fields    []Field
 	Timestamp time.Time
+	Stack     []StackFrame
+}
+
+// function 'KnownTraceId' returns the trace id of the record, empty when it was logged without a trace id
+// and carries the placeholder for a missing one
+func (r Record) KnownTraceId() string {
+	if r.TraceId == defaultTraceIdValue {
+		return ""
+	}
+	return r.TraceId
 }
@@ -0,0 +1,186 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// constant 'defaultReloadInterval' is the interval between checks of the configuration file in case of missing interval
+const defaultReloadInterval = 5 * time.Second
+
+// type 'Reopener' represents a handler writing to a file that can be moved away, e.g. by logrotate,
+// 'Reopen' is expected to reopen the file only when the open file is no longer at its path
+type Reopener interface {
+	Reopen() error
+}
+
+// struct 'Reloader' represents a watcher applying changes of a configuration file to a logger,
+// it reloads the file on SIGHUP and when its modification time or size changes,
+// level, formatters and handler destinations are reloaded while queue and worker settings
+// only take effect on restart
+type Reloader struct {
+	path     string
+	interval time.Duration
+	logger   *Logger
+	mu       sync.Mutex
+	owned    []Handler
+	extra    []Handler
+	modTime  time.Time
+	size     int64
+	signals  chan os.Signal
+	done     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// function 'NewReloadableLogger' creates a new logger from the configuration file at the given path
+// and a 'Reloader' watching the file every interval, a non-positive interval means 5 seconds,
+// a reloaded level is applied by 'Logger.SetLevel' so it reaches every child logger except those created by 'WithLevel',
+// reloaded handlers reach every child sharing the dispatcher of the logger while children created by
+// 'WithHandlers' keep their own handlers, handlers added by the given options are kept across reloads
+func NewReloadableLogger(path string, interval time.Duration, opts ...Option) (*Logger, *Reloader, error) {
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("logger config: %w", err)
+	}
+	c, err := LoadConfigFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	l, owned, err := newFromConfig(c, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	r := &Reloader{
+		path:     path,
+		interval: interval,
+		logger:   l,
+		owned:    owned,
+		extra:    l.Handlers[len(owned):],
+		modTime:  info.ModTime(),
+		size:     info.Size(),
+		signals:  make(chan os.Signal, 1),
+		done:     make(chan struct{}),
+	}
+	notifyReload(r.signals)
+	r.wg.Add(1)
+	go r.run()
+	return l, r, nil
+}
+
+// function 'Reload' reads the configuration file and applies it,
+// the new handlers replace the previous ones atomically and the previous ones are closed afterwards,
+// if the file cannot be applied, the current configuration is kept and the error is logged
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.reload(); err != nil {
+		r.logger.Error("logger configuration reload failed", String("path", r.path), Err(err))
+		return err
+	}
+	r.logger.Info("logger configuration reloaded", String("path", r.path))
+	return nil
+}
+
+// function 'reload' applies the configuration file, it is called with the lock held
+func (r *Reloader) reload() error {
+	if info, err := os.Stat(r.path); err == nil {
+		r.modTime, r.size = info.ModTime(), info.Size()
+	}
+
+	c, err := LoadConfigFile(r.path)
+	if err != nil {
+		return err
+	}
+	c, err = c.prepare()
+	if err != nil {
+		return err
+	}
+	level := Info
+	if c.Level != "" {
+		// errors are ignored as the configuration was validated by 'prepare'
+		level, _ = ParseLevel(c.Level)
+	}
+	owned, err := c.newHandlers()
+	if err != nil {
+		return err
+	}
+
+	handlers := make([]Handler, 0, len(owned)+len(r.extra))
+	handlers = append(handlers, owned...)
+	handlers = append(handlers, r.extra...)
+
+	previous := r.owned
+	r.logger.setHandlers(handlers)
+	r.owned = owned
+	r.logger.SetLevel(level)
+	// errors are ignored as the configuration was validated by 'prepare'
+	_ = r.logger.SetLevelOverrides(c.Levels)
+
+	if err := closeHandlers(previous); err != nil {
+		r.logger.Warn("closing previous log handlers failed", Err(err))
+	}
+	return nil
+}
+
+// function 'Close' stops watching the configuration file, the logger keeps its current configuration
+func (r *Reloader) Close() {
+	r.stopOnce.Do(func() {
+		stopReload(r.signals)
+		close(r.done)
+	})
+	r.wg.Wait()
+}
+
+// function 'run' reloads on signals and checks the configuration file and the open files every interval
+func (r *Reloader) run() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-r.signals:
+			_ = r.Reload()
+		case <-ticker.C:
+			if r.changed() {
+				_ = r.Reload()
+				continue
+			}
+			r.reopen()
+		}
+	}
+}
+
+// function 'changed' reports whether the modification time or size of the configuration file changed
+func (r *Reloader) changed() bool {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		// a missing file is reported by the next reload triggered by a change
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !info.ModTime().Equal(r.modTime) || info.Size() != r.size
+}
+
+// function 'reopen' reopens the files of the handlers that were moved away
+func (r *Reloader) reopen() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, h := range r.owned {
+		if reopener, ok := h.(Reopener); ok {
+			if err := reopener.Reopen(); err != nil {
+				r.logger.Error("reopening log file failed", Err(err))
+			}
+		}
+	}
+}
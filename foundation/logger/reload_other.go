@@ -0,0 +1,12 @@
+//go:build !unix
+
+package logger
+
+import "os"
+
+// function 'notifyReload' does nothing as SIGHUP is not available on this platform,
+// the configuration file is still reloaded when it changes
+func notifyReload(ch chan os.Signal) {}
+
+// function 'stopReload' does nothing as no signal is relayed on this platform
+func stopReload(ch chan os.Signal) {}
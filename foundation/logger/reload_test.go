@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func init() {
+	RegisterHandler("collect", func(HandlerConfig, Formatter) (Handler, error) {
+		return &collectingHandler{}, nil
+	})
+}
+
+func TestReloadKeepsChildLevelsAndUpdatesHandlers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logger.json")
+	writeConfig := func(level string) {
+		data := `{"level": "` + level + `", "levels": "db=error", "handlers": [{"type": "collect"}]}`
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig("warn")
+
+	extra := &collectingHandler{}
+	l, r, err := NewReloadableLogger(path, time.Hour, WithHandler(extra))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	defer r.Close()
+
+	quiet := l.WithLevel(Error)
+	named := l.Named("api")
+	db := l.Named("db")
+	if named.Enabled(Info) || !named.Enabled(Warn) {
+		t.Errorf("named logger does not follow the level of the file")
+	}
+
+	writeConfig("debug")
+	if err := r.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !l.Enabled(Debug) || !named.Enabled(Debug) {
+		t.Errorf("reloaded level did not reach the logger and its children")
+	}
+	if quiet.Enabled(Warn) {
+		t.Errorf("reloaded level overrode the level set by WithLevel")
+	}
+	if db.Enabled(Warn) {
+		t.Errorf("level overrides of the file were not applied")
+	}
+
+	for _, logger := range []*Logger{l, l.With(String("k", "v"))} {
+		if len(logger.Handlers) != 2 || logger.Handlers[1] != Handler(extra) || logger.Handlers[0] != r.owned[0] {
+			t.Errorf("got handlers %v, want the reloaded ones", logger.Handlers)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// function 'notifyReload' relays SIGHUP to the given channel
+func notifyReload(ch chan os.Signal) {
+	signal.Notify(ch, syscall.SIGHUP)
+}
+
+// function 'stopReload' stops relaying signals to the given channel
+func stopReload(ch chan os.Signal) {
+	signal.Stop(ch)
+}
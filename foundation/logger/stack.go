@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// constant 'defaultStackDepth' is the maximum number of captured frames in case of missing depth
+const defaultStackDepth = 32
+
+// struct 'StackFrame' represents a single frame of a captured stack trace
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// function 'captureStack' captures the stack of the calling goroutine,
+// skip counts the frames above the function calling 'captureStack', as in 'runtime.Caller',
+// the runtime frames at the bottom of the stack are trimmed
+func captureStack(skip, depth int) []StackFrame {
+	if depth <= 0 {
+		depth = defaultStackDepth
+	}
+
+	pcs := make([]uintptr, depth)
+	n := runtime.Callers(skip+1, pcs)
+	if n == 0 {
+		return nil
+	}
+
+	frames := runtime.CallersFrames(pcs[:n])
+	stack := make([]StackFrame, 0, n)
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.goexit" || frame.Function == "runtime.main" {
+			break
+		}
+		stack = append(stack, StackFrame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// function 'CaptureStack' captures the stack of the calling goroutine starting at the function calling it,
+// skip counts additional frames to leave out and depth limits the number of frames, 32 when not positive,
+// it is used to attach a stack to errors implementing 'StackTracer', e.g. recovered panics
+func CaptureStack(skip, depth int) []StackFrame {
+	return captureStack(skip+2, depth)
+}
+
+// function 'StackString' renders the given frames the way the Go runtime prints goroutine stacks
+func StackString(stack []StackFrame) string {
+	var sb strings.Builder
+	for i, frame := range stack {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(frame.Function)
+		sb.WriteString("\n\t")
+		sb.WriteString(frame.File)
+		sb.WriteByte(':')
+		sb.WriteString(strconv.Itoa(frame.Line))
+	}
+	return sb.String()
+}
+
+// function 'parseStack' parses frames rendered by 'StackString'
+func parseStack(s string) []StackFrame {
+	lines := strings.Split(s, "\n")
+	stack := make([]StackFrame, 0, len(lines)/2)
+	for i := 0; i+1 < len(lines); i += 2 {
+		frame := StackFrame{Function: lines[i], File: strings.TrimPrefix(lines[i+1], "\t")}
+		if j := strings.LastIndexByte(frame.File, ':'); j >= 0 {
+			if line, err := strconv.Atoi(frame.File[j+1:]); err == nil {
+				frame.File, frame.Line = frame.File[:j], line
+			}
+		}
+		stack = append(stack, frame)
+	}
+	return stack
+}
@@ -0,0 +1,125 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger/loggertest"
+)
+
+// variable 'fixedClock' stamps every record with the same time so formatted output is reproducible
+var fixedClock = logger.ClockFunc(func() time.Time {
+	return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+})
+
+func TestLoggerLevels(t *testing.T) {
+	l, rec := loggertest.NewTestLogger(t, logger.WithLevel(logger.Info))
+
+	l.Debug("hidden")
+	l.Info("shown")
+	l.Warn("careful")
+	l.Error("failed", logger.Err(errors.New("disk full")))
+
+	loggertest.CountByLevel(t, rec, logger.Debug, 0)
+	loggertest.CountByLevel(t, rec, logger.Info, 1)
+	loggertest.CountByLevel(t, rec, logger.Warn, 1)
+	loggertest.HasRecord(t, rec, loggertest.Level(logger.Error), loggertest.Field("error", "disk full"))
+}
+
+func TestLoggerChildren(t *testing.T) {
+	l, rec := loggertest.NewTestLogger(t, logger.WithLevel(logger.Info), logger.WithLevelOverrides("db=error"))
+
+	api := l.Named("api").With(logger.String("service", "orders"))
+	api.Info("listening", logger.Int("port", 8080))
+	loggertest.HasRecord(t, rec,
+		loggertest.Message("listening"),
+		loggertest.Field("logger", "api"),
+		loggertest.Field("service", "orders"),
+		loggertest.Field("port", 8080),
+	)
+
+	db := l.Named("db")
+	db.Warn("slow query")
+	db.Error("connection lost")
+	if rec.Count(loggertest.Field("logger", "db")) != 1 {
+		t.Errorf("level override of db was not applied")
+	}
+
+	l.WithLevel(logger.Debug).Debug("verbose")
+	loggertest.HasRecord(t, rec, loggertest.Message("verbose"), loggertest.Level(logger.Debug))
+
+	api.With(logger.String("service", "billing")).Info("moved")
+	loggertest.HasRecord(t, rec, loggertest.Message("moved"), loggertest.Field("service", "billing"))
+}
+
+func TestLoggerContext(t *testing.T) {
+	l, rec := loggertest.NewTestLogger(t)
+
+	ctx := logger.ContextWithTraceId(context.Background(), "", "4bf92f3577b34da6a3ce929d0e0e4736")
+	ctx = logger.ContextWithFields(ctx, logger.String("request_id", "r-1"))
+	l.InfoCtx(ctx, "handled", logger.String("request_id", "r-2"))
+	l.Info("untraced")
+
+	got := loggertest.HasRecord(t, rec, loggertest.Message("handled"), loggertest.Field("request_id", "r-2"))
+	if got.TraceId != "4bf92f3577b34da6a3ce929d0e0e4736" || len(got.Fields) != 1 {
+		t.Errorf("got %+v", got)
+	}
+	if untraced := loggertest.HasRecord(t, rec, loggertest.Message("untraced")); untraced.KnownTraceId() != "" {
+		t.Errorf("got trace id %q for an untraced record", untraced.TraceId)
+	}
+	loggertest.NoErrors(t, rec)
+}
+
+func TestLoggerAudit(t *testing.T) {
+	l, rec := loggertest.NewTestLogger(t, logger.WithLevel(logger.Error))
+
+	logger.NewAuditor(l).Log(context.Background(), logger.AuditEvent{Actor: "bob", Action: "login", Outcome: "success"})
+	loggertest.HasRecord(t, rec, loggertest.Level(logger.Audit), loggertest.Message("audit: login"))
+	loggertest.NoErrors(t, rec)
+}
+
+func TestLoggerCloseTwice(t *testing.T) {
+	l, rec := loggertest.NewTestLogger(t)
+	l.Info("before close")
+	// 'NewTestLogger' closes the logger again when the test ends
+	l.Close()
+	l.Close()
+	loggertest.CountByLevel(t, rec, logger.Info, 1)
+}
+
+func TestLoggerGolden(t *testing.T) {
+	for name, golden := range map[string]struct {
+		formatter logger.Formatter
+		caller    logger.CallerFormat
+	}{
+		"json":   {logger.NewJSONFormatter(), logger.CallerBasename},
+		"logfmt": {logger.NewLogfmtFormatter(), logger.CallerBasename},
+		"dev":    {&logger.DevFormatter{MessageWidth: 20}, logger.CallerBasename},
+		// ECS splits the caller into file and line which 'loggertest.Normalize' cannot replace
+		"ecs": {logger.NewProfileFormatter(logger.ECSProfile()), logger.CallerNone},
+	} {
+		t.Run(name, func(t *testing.T) {
+			l, rec := loggertest.NewTestLogger(t, logger.WithClock(fixedClock), logger.WithCallerFormat(golden.caller))
+			logGoldenScenario(l)
+			loggertest.GoldenRecords(t, rec, golden.formatter, "testdata/"+name+".golden")
+		})
+	}
+}
+
+// function 'logGoldenScenario' logs the records compared with the golden files
+func logGoldenScenario(l *logger.Logger) {
+	ctx := logger.ContextWithTraceId(context.Background(), "", "4bf92f3577b34da6a3ce929d0e0e4736")
+	api := l.Named("api").With(logger.String("service", "orders"))
+
+	api.Debug("cache miss", logger.String("key", "order:42"))
+	api.InfoCtx(ctx, "order created", logger.Int("order_id", 42), logger.Bool("express", true))
+	api.Warn("slow response", logger.Field{Key: "elapsed", Value: 1500 * time.Millisecond})
+	api.ErrorCtx(ctx, "payment failed",
+		logger.Err(fmt.Errorf("charge card: %w", errors.New("card declined"))),
+		logger.Group("card", logger.String("brand", "visa"), logger.Int("last4", 4242)),
+	)
+}
@@ -0,0 +1,190 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+	"unicode/utf8"
+)
+
+// variable 'textColors' maps the color names accepted by the 'color' template function to ANSI sequences
+var textColors = map[string]string{
+	"bold":    ansiBold,
+	"dim":     ansiDim,
+	"red":     ansiRed,
+	"green":   ansiGreen,
+	"yellow":  ansiYellow,
+	"blue":    ansiBlue,
+	"magenta": ansiMagenta,
+}
+
+// variable 'plainTextKeys' are the keys whose string values are rendered by the compiled fast path
+var plainTextKeys = map[string]bool{
+	"level":     true,
+	"message":   true,
+	"caller":    true,
+	"fields":    true,
+	"timestamp": true,
+	"trace_id":  true,
+}
+
+// struct 'boundTemplate' represents a template whose 'field' function reads the record in 'rec'
+type boundTemplate struct {
+	tmpl *template.Template
+	rec  *Record
+}
+
+// struct 'textSegment' represents a part of a compiled pattern,
+// it is either literal text or the key of a value to write
+type textSegment struct {
+	text string
+	key  string
+}
+
+// function 'funcs' returns the functions available to text patterns:
+//   - upper: converts a value to upper case, e.g. {{upper .level}}
+//   - pad: pads a value to a width, a negative width aligns right, e.g. {{pad 5 .level}}
+//   - color: colors a value when color is enabled, e.g. {{color "red" .message}}
+//   - timefmt: formats a time with a layout, e.g. {{timefmt "15:04:05" .time}}
+//   - json: encodes a value as JSON, e.g. {{json .record.Fields}}
+//   - default: returns a fallback for empty values, e.g. {{default "-" .trace_id}}
+//   - field: returns the value of a single field, e.g. {{field "user_id"}}
+func (f *TextFormatter) funcs(t *boundTemplate) template.FuncMap {
+	return template.FuncMap{
+		"upper": func(v any) string {
+			return strings.ToUpper(fmt.Sprint(v))
+		},
+		"pad": func(width int, v any) string {
+			s := fmt.Sprint(v)
+			n := utf8.RuneCountInString(s)
+			if width < 0 {
+				if -width > n {
+					return strings.Repeat(" ", -width-n) + s
+				}
+				return s
+			}
+			if width > n {
+				return s + strings.Repeat(" ", width-n)
+			}
+			return s
+		},
+		"color": func(name string, v any) string {
+			s := fmt.Sprint(v)
+			code, ok := textColors[name]
+			if !f.Color || !ok {
+				return s
+			}
+			return code + s + ansiReset
+		},
+		"timefmt": func(layout string, t time.Time) string {
+			return t.Format(layout)
+		},
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(jsonValue(v))
+			return string(b), err
+		},
+		"default": func(fallback, v any) any {
+			if v == nil {
+				return fallback
+			}
+			if rv := reflect.ValueOf(v); rv.IsZero() {
+				return fallback
+			}
+			return v
+		},
+		"field": func(key string) any {
+			if t == nil || t.rec == nil {
+				return nil
+			}
+			for i := len(t.rec.Fields) - 1; i >= 0; i-- {
+				if t.rec.Fields[i].Key == key {
+					return t.rec.Fields[i].Value
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// function 'textData' returns the data a pattern is executed with,
+// the encoded timestamp is also available under the key of the timestamp encoder
+func (f *TextFormatter) textData(r Record) map[string]any {
+	timestamp := f.Timestamp.String(r.Timestamp)
+	data := map[string]any{
+		"level":     r.Level.String(),
+		"message":   r.Message,
+		"caller":    r.Caller,
+		"fields":    fieldsText(r.Fields),
+		"timestamp": timestamp,
+		"trace_id":  r.TraceId,
+		"time":      r.Timestamp,
+		"record":    r,
+	}
+	data[f.Timestamp.key("timestamp")] = timestamp
+	return data
+}
+
+// function 'textValue' returns the value of a plain key used by the compiled fast path
+func (f *TextFormatter) textValue(key string, r Record) string {
+	switch key {
+	case "level":
+		return r.Level.String()
+	case "message":
+		return r.Message
+	case "caller":
+		return r.Caller
+	case "fields":
+		return fieldsText(r.Fields)
+	case "timestamp":
+		return f.Timestamp.String(r.Timestamp)
+	case "trace_id":
+		return r.TraceId
+	default:
+		return ""
+	}
+}
+
+// function 'fieldsText' returns the fields as space separated key=value pairs
+func fieldsText(fields []Field) string {
+	var sb bytes.Buffer
+	for i, field := range fields {
+		sb.WriteString(fmt.Sprintf("%s=%v", field.Key, field.Value))
+		if i < len(fields)-1 {
+			sb.WriteByte(' ')
+		}
+	}
+	return sb.String()
+}
+
+// function 'compileTemplate' compiles a parsed pattern into segments,
+// it returns nil when the pattern uses anything but literal text and plain string keys
+func compileTemplate(tmpl *template.Template) []textSegment {
+	if tmpl.Tree == nil || tmpl.Tree.Root == nil || len(tmpl.Templates()) > 1 {
+		return nil
+	}
+
+	var segments []textSegment
+	for _, node := range tmpl.Tree.Root.Nodes {
+		switch n := node.(type) {
+		case *parse.TextNode:
+			segments = append(segments, textSegment{text: string(n.Text)})
+		case *parse.ActionNode:
+			if len(n.Pipe.Decl) > 0 || len(n.Pipe.Cmds) != 1 || len(n.Pipe.Cmds[0].Args) != 1 {
+				return nil
+			}
+			field, ok := n.Pipe.Cmds[0].Args[0].(*parse.FieldNode)
+			if !ok || len(field.Ident) != 1 || !plainTextKeys[field.Ident[0]] {
+				return nil
+			}
+			segments = append(segments, textSegment{key: field.Ident[0]})
+		default:
+			return nil
+		}
+	}
+	return segments
+}
@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"strconv"
+	"time"
+)
+
+// type 'Clock' represents a source of the current time, it is used by the logger to timestamp records
+type Clock interface {
+	Now() time.Time
+}
+
+// type 'ClockFunc' adapts a function to 'Clock', e.g. a fixed time for golden tests
+type ClockFunc func() time.Time
+
+// function 'Now' returns the time returned by the function
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// struct 'systemClock' implements 'Clock' interface using the system time
+type systemClock struct{}
+
+// function 'Now' returns the current system time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// type 'TimestampFormat' represents the encoding of a record timestamp
+type TimestampFormat int
+
+// constants for timestamp formats,
+// the RFC 3339 formats differ in the precision of the fractional seconds,
+// 'TimestampRFC3339Nano' trims trailing zeros while the others always write their digits,
+// the epoch formats are integers counting since the Unix epoch,
+// 'TimestampLayout' uses the custom layout of 'TimestampEncoder'
+const (
+	TimestampRFC3339Nano TimestampFormat = iota
+	TimestampRFC3339
+	TimestampRFC3339Milli
+	TimestampRFC3339Micro
+	TimestampEpochSeconds
+	TimestampEpochMillis
+	TimestampEpochNanos
+	TimestampLayout
+)
+
+// struct 'TimestampEncoder' represents how formatters write the record timestamp,
+// 'Key' overrides the name of the timestamp field, e.g. "@timestamp" or "ts",
+// 'Location' converts the timestamp before formatting, e.g. 'time.UTC' or 'time.Local',
+// the zero value keeps the default key of the formatter and writes RFC 3339 with nanoseconds
+type TimestampEncoder struct {
+	Key      string
+	Format   TimestampFormat
+	Layout   string
+	Location *time.Location
+}
+
+// function 'Encode' encodes the given time, epoch formats return an int64 and the others a string
+func (e TimestampEncoder) Encode(t time.Time) any {
+	if e.Location != nil {
+		t = t.In(e.Location)
+	}
+
+	switch e.Format {
+	case TimestampRFC3339:
+		return t.Format(time.RFC3339)
+	case TimestampRFC3339Milli:
+		return t.Format("2006-01-02T15:04:05.000Z07:00")
+	case TimestampRFC3339Micro:
+		return t.Format("2006-01-02T15:04:05.000000Z07:00")
+	case TimestampEpochSeconds:
+		return t.Unix()
+	case TimestampEpochMillis:
+		return t.UnixMilli()
+	case TimestampEpochNanos:
+		return t.UnixNano()
+	case TimestampLayout:
+		if e.Layout != "" {
+			return t.Format(e.Layout)
+		}
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// function 'String' encodes the given time as a string
+func (e TimestampEncoder) String(t time.Time) string {
+	switch v := e.Encode(t).(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	default:
+		return v.(string)
+	}
+}
+
+// function 'key' returns the timestamp field name or the given default
+func (e TimestampEncoder) key(fallback string) string {
+	if e.Key != "" {
+		return e.Key
+	}
+	return fallback
+}
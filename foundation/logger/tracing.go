@@ -26,3 +26,22 @@ func getTraceId(key string, ctx context.Context) string {
 	}
 	return defaultTraceIdValue
 }
+
+// function 'ContextWithTraceId' returns a copy of the context carrying the given traceId under the given key,
+// it is the counterpart of 'getTraceId' so the key has to match the one given to 'WithTraceIdKey',
+// if the key is empty, it uses the default key defined as 'defaultTraceIdKey'
+func ContextWithTraceId(ctx context.Context, key, traceId string) context.Context {
+	if key == "" {
+		key = string(defaultTraceIdKey)
+	}
+	return context.WithValue(ctx, key, traceId)
+}
+
+// function 'TraceIdFromContext' returns the traceId carried by the context under the given key,
+// if the value is missing, it returns an empty string
+func TraceIdFromContext(ctx context.Context, key string) string {
+	if traceId := getTraceId(key, ctx); traceId != defaultTraceIdValue {
+		return traceId
+	}
+	return ""
+}
@@ -0,0 +1,439 @@
+package logger
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// type 'SyncPolicy' represents when the write-ahead log is flushed to stable storage
+type SyncPolicy int
+
+// constants for sync policies,
+// 'SyncAlways' syncs after every record so no acknowledged record is lost, at the cost of one fsync per record,
+// 'SyncInterval' syncs every 'WALConfig.SyncInterval' so at most one interval of records is lost on power failure,
+// 'SyncNever' leaves flushing to the operating system, records survive a crash of the process but not of the host
+const (
+	SyncAlways SyncPolicy = iota
+	SyncInterval
+	SyncNever
+)
+
+// constants for the write-ahead log defaults and segment layout
+const (
+	defaultSegmentSize  = 16 << 20
+	defaultSyncInterval = time.Second
+	walHeader           = "LOGWAL01"
+	walEntryHeaderSize  = 8
+	walSegmentExt       = ".wal"
+	walAckExt           = ".ack"
+	walAckSize          = 8
+)
+
+// variable 'walTable' is the CRC-32C table used to checksum entries
+var walTable = crc32.MakeTable(crc32.Castagnoli)
+
+// struct 'WALConfig' represents the configuration of the disk-backed queue of the dispatcher,
+// 'Dir' holds the segment files and must not be shared by two loggers,
+// 'SegmentSize' is the size after which a new segment is started, 16 MiB when not positive
+type WALConfig struct {
+	Dir          string
+	Sync         SyncPolicy
+	SyncInterval time.Duration
+	SegmentSize  int64
+}
+
+// struct 'wal' represents a write-ahead log made of segment files,
+// every record is appended before it is queued and acknowledged once the handlers returned,
+// a segment is removed when it is full and all of its records were acknowledged,
+// next to every segment an ack file holds the number of leading records that were acknowledged
+// so a restart replays only the records after them
+type wal struct {
+	config   WALConfig
+	report   func(error)
+	mu       sync.Mutex
+	active   *walSegment
+	sealed   []*walSegment
+	replayed []string
+	nextId   uint64
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// struct 'walSegment' represents a segment file and the progress of its records,
+// 'acked' counts the leading records that were acknowledged and is persisted in 'ackFile',
+// 'ackedAhead' holds the indexes of records acknowledged out of order after them
+type walSegment struct {
+	id         uint64
+	path       string
+	file       *os.File
+	ackFile    *os.File
+	size       int64
+	appended   int
+	acked      int
+	ackedAhead map[int]bool
+	dirty      bool
+}
+
+// function 'openWAL' opens the write-ahead log in the configured directory,
+// it returns the records of the segments left by a previous run that follow their acknowledged records,
+// segments are read up to the first truncated or corrupted entry which is reported,
+// these segments are removed by 'removeReplayed'
+func openWAL(config WALConfig, report func(error)) (*wal, []Record, error) {
+	if config.Dir == "" {
+		return nil, nil, errors.New("wal: directory is required")
+	}
+	if config.SegmentSize <= 0 {
+		config.SegmentSize = defaultSegmentSize
+	}
+	if config.SyncInterval <= 0 {
+		config.SyncInterval = defaultSyncInterval
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, nil, fmt.Errorf("wal: %w", err)
+	}
+
+	w := &wal{config: config, report: report, done: make(chan struct{}), nextId: 1}
+	ids, err := w.segmentIds()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var recovered []Record
+	for _, id := range ids {
+		path := w.segmentPath(id)
+		records, err := readSegment(path, readAcked(path))
+		if err != nil {
+			report(fmt.Errorf("wal: %s: %w, recovered %d records before the damage", path, err, len(records)))
+		}
+		recovered = append(recovered, records...)
+		w.replayed = append(w.replayed, path)
+		w.nextId = id + 1
+	}
+
+	if err := w.rotate(); err != nil {
+		return nil, nil, err
+	}
+	if config.Sync == SyncInterval {
+		w.wg.Add(1)
+		go w.syncLoop()
+	}
+	return w, recovered, nil
+}
+
+// function 'append' appends a record to the active segment and returns the segment holding it
+// and the index of the record within the segment,
+// records larger than 'maxFrameSize' are rejected as replay would stop at them as if the segment was damaged
+func (w *wal) append(r Record) (*walSegment, int, error) {
+	payload := cborAppend(nil, binaryRecord(r))
+	if len(payload) > maxFrameSize {
+		return nil, 0, fmt.Errorf("wal: record of %d bytes exceeds limit of %d bytes, it is delivered without write-ahead log", len(payload), maxFrameSize)
+	}
+	entry := make([]byte, walEntryHeaderSize, walEntryHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(entry[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(entry[4:8], crc32.Checksum(payload, walTable))
+	entry = append(entry, payload...)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.active.appended > 0 && w.active.size+int64(len(entry)) > w.config.SegmentSize {
+		if err := w.rotate(); err != nil {
+			// the active segment is kept and grows beyond its size until a rotation succeeds
+			w.report(err)
+		}
+	}
+
+	seg := w.active
+	if _, err := seg.file.Write(entry); err != nil {
+		return nil, 0, fmt.Errorf("wal: append to %s: %w", seg.path, err)
+	}
+	index := seg.appended
+	seg.size += int64(len(entry))
+	seg.appended++
+
+	if w.config.Sync == SyncAlways {
+		if err := seg.file.Sync(); err != nil {
+			return seg, index, fmt.Errorf("wal: sync %s: %w", seg.path, err)
+		}
+	} else {
+		seg.dirty = true
+	}
+	return seg, index, nil
+}
+
+// function 'ack' acknowledges the record with the given index of the given segment,
+// the number of leading acknowledged records is written to the ack file of the segment,
+// the segment is compacted once it is sealed and all of its records were acknowledged
+func (w *wal) ack(seg *walSegment, index int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if index != seg.acked {
+		if seg.ackedAhead == nil {
+			seg.ackedAhead = map[int]bool{}
+		}
+		seg.ackedAhead[index] = true
+		return
+	}
+	seg.acked++
+	for seg.ackedAhead[seg.acked] {
+		delete(seg.ackedAhead, seg.acked)
+		seg.acked++
+	}
+
+	if seg == w.active || seg.acked < seg.appended {
+		w.writeAcked(seg)
+		return
+	}
+	for i, s := range w.sealed {
+		if s == seg {
+			w.sealed = append(w.sealed[:i], w.sealed[i+1:]...)
+			break
+		}
+	}
+	w.remove(seg)
+}
+
+// function 'writeAcked' writes the number of leading acknowledged records to the ack file of the segment,
+// the file is not synced as a lost update only causes records to be delivered again
+func (w *wal) writeAcked(seg *walSegment) {
+	var b [walAckSize]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(seg.acked))
+	if _, err := seg.ackFile.WriteAt(b[:], 0); err != nil {
+		w.report(fmt.Errorf("wal: write acknowledgements of %s: %w", seg.path, err))
+	}
+}
+
+// function 'remove' closes the ack file of the segment and removes the segment and its ack file
+func (w *wal) remove(seg *walSegment) {
+	if err := seg.ackFile.Close(); err != nil {
+		w.report(fmt.Errorf("wal: close %s: %w", ackPath(seg.path), err))
+	}
+	removeSegment(seg.path, w.report)
+}
+
+// function 'removeSegment' removes a segment file and its ack file, a missing file is not an error
+func removeSegment(path string, report func(error)) {
+	for _, p := range []string{path, ackPath(path)} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			report(fmt.Errorf("wal: compact %s: %w", p, err))
+		}
+	}
+}
+
+// function 'removeReplayed' removes the segments of the previous run once their records were replayed
+func (w *wal) removeReplayed() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, path := range w.replayed {
+		removeSegment(path, w.report)
+	}
+	w.replayed = nil
+}
+
+// function 'close' syncs and closes the segments, segments whose records were all acknowledged are removed
+// while the others are kept to be replayed on the next start
+func (w *wal) close() {
+	close(w.done)
+	w.wg.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	seg := w.active
+	if err := seg.file.Sync(); err != nil {
+		w.report(fmt.Errorf("wal: sync %s: %w", seg.path, err))
+	}
+	if err := seg.file.Close(); err != nil {
+		w.report(fmt.Errorf("wal: close %s: %w", seg.path, err))
+	}
+	if seg.acked >= seg.appended {
+		w.remove(seg)
+	} else {
+		w.closeAckFile(seg)
+	}
+	for _, s := range w.sealed {
+		w.closeAckFile(s)
+	}
+	w.sealed = nil
+}
+
+// function 'closeAckFile' syncs and closes the ack file of a segment kept for the next start
+func (w *wal) closeAckFile(seg *walSegment) {
+	if err := seg.ackFile.Sync(); err != nil {
+		w.report(fmt.Errorf("wal: sync %s: %w", ackPath(seg.path), err))
+	}
+	if err := seg.ackFile.Close(); err != nil {
+		w.report(fmt.Errorf("wal: close %s: %w", ackPath(seg.path), err))
+	}
+}
+
+// function 'rotate' seals the active segment and starts a new one, it is called with the lock held
+// or before the log is shared, the new segment is created first so the active segment is kept
+// when the new one cannot be created
+func (w *wal) rotate() error {
+	next, err := w.createSegment(w.nextId)
+	if err != nil {
+		return err
+	}
+	w.nextId++
+
+	if seg := w.active; seg != nil {
+		if err := seg.file.Sync(); err != nil {
+			w.report(fmt.Errorf("wal: sync %s: %w", seg.path, err))
+		}
+		if err := seg.file.Close(); err != nil {
+			w.report(fmt.Errorf("wal: close %s: %w", seg.path, err))
+		}
+		seg.dirty = false
+		if seg.acked >= seg.appended {
+			w.remove(seg)
+		} else {
+			w.sealed = append(w.sealed, seg)
+		}
+	}
+	w.active = next
+	return nil
+}
+
+// function 'createSegment' creates the segment file with the given id and its ack file
+func (w *wal) createSegment(id uint64) (*walSegment, error) {
+	path := w.segmentPath(id)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("wal: create segment: %w", err)
+	}
+	ackFile, err := os.OpenFile(ackPath(path), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err == nil {
+		_, err = file.WriteString(walHeader)
+		if err != nil {
+			ackFile.Close()
+		}
+	}
+	if err != nil {
+		file.Close()
+		// the files are removed so the next attempt can create them again
+		removeSegment(path, w.report)
+		return nil, fmt.Errorf("wal: create segment: %w", err)
+	}
+	return &walSegment{id: id, path: path, file: file, ackFile: ackFile, size: int64(len(walHeader))}, nil
+}
+
+// function 'syncLoop' syncs the active segment every interval when it has unsynced records
+func (w *wal) syncLoop() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			w.mu.Lock()
+			if seg := w.active; seg.dirty {
+				if err := seg.file.Sync(); err != nil {
+					w.report(fmt.Errorf("wal: sync %s: %w", seg.path, err))
+				}
+				seg.dirty = false
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// function 'segmentIds' returns the ids of the segment files in the directory in ascending order
+func (w *wal) segmentIds() ([]uint64, error) {
+	entries, err := os.ReadDir(w.config.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("wal: %w", err)
+	}
+	var ids []uint64
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), walSegmentExt)
+		if !ok || e.IsDir() {
+			continue
+		}
+		if id, err := strconv.ParseUint(name, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// function 'segmentPath' returns the path of the segment with the given id
+func (w *wal) segmentPath(id uint64) string {
+	return filepath.Join(w.config.Dir, fmt.Sprintf("%020d%s", id, walSegmentExt))
+}
+
+// function 'ackPath' returns the path of the ack file of the segment at the given path
+func ackPath(path string) string {
+	return strings.TrimSuffix(path, walSegmentExt) + walAckExt
+}
+
+// function 'readAcked' returns the number of leading acknowledged records of the segment at the given path,
+// zero when its ack file is missing or incomplete
+func readAcked(path string) int {
+	b, err := os.ReadFile(ackPath(path))
+	if err != nil || len(b) < walAckSize {
+		return 0
+	}
+	return int(min(binary.LittleEndian.Uint64(b), math.MaxInt32))
+}
+
+// function 'readSegment' reads the records of a segment file after the given number of acknowledged ones,
+// it returns the records read before a truncated or corrupted entry together with the error
+func readSegment(path string, acked int) ([]Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(string(data), walHeader) {
+		if len(data) < len(walHeader) {
+			// the process stopped while creating the segment
+			return nil, nil
+		}
+		return nil, errors.New("not a segment file")
+	}
+
+	var records []Record
+	formatter := &CBORFormatter{}
+	for offset, index := len(walHeader), 0; offset < len(data); index++ {
+		if len(data)-offset < walEntryHeaderSize {
+			return records, fmt.Errorf("entry header at offset %d: %w", offset, io.ErrUnexpectedEOF)
+		}
+		size := int(binary.LittleEndian.Uint32(data[offset : offset+4]))
+		sum := binary.LittleEndian.Uint32(data[offset+4 : offset+8])
+		start := offset + walEntryHeaderSize
+		if size > maxFrameSize || len(data)-start < size {
+			return records, fmt.Errorf("entry of %d bytes at offset %d: %w", size, offset, io.ErrUnexpectedEOF)
+		}
+		payload := data[start : start+size]
+		if index < acked {
+			offset = start + size
+			continue
+		}
+		if crc32.Checksum(payload, walTable) != sum {
+			return records, fmt.Errorf("entry at offset %d: checksum mismatch", offset)
+		}
+		r, err := formatter.Decode(payload)
+		if err != nil {
+			return records, fmt.Errorf("entry at offset %d: %w", offset, err)
+		}
+		records = append(records, r)
+		offset = start + size
+	}
+	return records, nil
+}
@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWALRejectsOversizedRecords(t *testing.T) {
+	dir := t.TempDir()
+	var reported []error
+	w, _, err := openWAL(WALConfig{Dir: dir}, func(err error) { reported = append(reported, err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := w.append(Record{Level: Info, Message: "before"}); err != nil {
+		t.Fatal(err)
+	}
+	if seg, _, err := w.append(Record{Level: Info, Message: strings.Repeat("x", maxFrameSize)}); err == nil || seg != nil {
+		t.Errorf("got %v, %v, want the oversized record rejected", seg, err)
+	}
+	if _, _, err := w.append(Record{Level: Info, Message: "after"}); err != nil {
+		t.Fatal(err)
+	}
+	w.close()
+
+	w, recovered, err := openWAL(WALConfig{Dir: dir}, func(err error) { reported = append(reported, err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.close()
+	if len(recovered) != 2 || recovered[0].Message != "before" || recovered[1].Message != "after" {
+		t.Errorf("got %d recovered records, want the records around the oversized one", len(recovered))
+	}
+	if len(reported) > 0 {
+		t.Errorf("replay reported %v", reported)
+	}
+}
+
+func TestWALReplaysOnlyUnacknowledgedRecords(t *testing.T) {
+	dir := t.TempDir()
+	h := &collectingHandler{}
+	d := NewSyncDispatcher([]Handler{h}, nil, nil)
+	if err := d.OpenWAL(WALConfig{Dir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range []string{"one", "two", "three"} {
+		d.Dispatch(context.Background(), Record{Level: Info, Message: msg})
+	}
+	// a record appended without being acknowledged, as when the process stops during its delivery
+	if _, _, err := d.wal.append(Record{Level: Info, Message: "in flight"}); err != nil {
+		t.Fatal(err)
+	}
+	// the process crashes, neither the dispatcher nor the log are closed
+
+	replayed := &collectingHandler{}
+	restarted := NewSyncDispatcher([]Handler{replayed}, nil, nil)
+	if err := restarted.OpenWAL(WALConfig{Dir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.Close()
+	if got := replayed.messages(); len(got) != 1 || got[0] != "in flight" {
+		t.Errorf("replayed %v, want only the unacknowledged record", got)
+	}
+}
+
+func TestWALAcknowledgementsOutOfOrder(t *testing.T) {
+	dir := t.TempDir()
+	w, _, err := openWAL(WALConfig{Dir: dir}, func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	var indexes []int
+	seg := w.active
+	for _, msg := range []string{"a", "b", "c", "d"} {
+		_, index, err := w.append(Record{Level: Info, Message: msg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		indexes = append(indexes, index)
+	}
+	w.ack(seg, indexes[1])
+	w.ack(seg, indexes[0])
+	w.ack(seg, indexes[3])
+	if seg.acked != 2 {
+		t.Errorf("got %d leading acknowledged records, want 2", seg.acked)
+	}
+	if got := readAcked(seg.path); got != 2 {
+		t.Errorf("ack file holds %d, want 2", got)
+	}
+
+	records, err := readSegment(seg.path, readAcked(seg.path))
+	if err != nil || len(records) != 2 || records[0].Message != "c" {
+		t.Errorf("got %v, %v", records, err)
+	}
+	w.close()
+}
+
+func TestWALKeepsActiveSegmentWhenRotationFails(t *testing.T) {
+	dir := t.TempDir()
+	var reported []error
+	w, _, err := openWAL(WALConfig{Dir: dir, SegmentSize: 1}, func(err error) { reported = append(reported, err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.close()
+
+	// a file in the way of the next segment makes the rotation fail
+	blocker := w.segmentPath(w.nextId)
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	first, _, err := w.append(Record{Level: Info, Message: "first"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _, err := w.append(Record{Level: Info, Message: "second"})
+	if err != nil || second != first {
+		t.Fatalf("append after a failed rotation: %v", err)
+	}
+	if len(reported) != 1 {
+		t.Errorf("got reported %v, want the failed rotation", reported)
+	}
+
+	if err := os.Remove(blocker); err != nil {
+		t.Fatal(err)
+	}
+	third, _, err := w.append(Record{Level: Info, Message: "third"})
+	if err != nil || third == first {
+		t.Errorf("the log did not rotate once the segment could be created: %v", err)
+	}
+}
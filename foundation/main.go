@@ -2,27 +2,33 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"os"
 
 	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
-	"github.com/eskandaridanial/go-starter-kit/foundation/logger/handlers"
+	// the handlers package registers the "console" and "file" handler types used by the configuration
+	_ "github.com/eskandaridanial/go-starter-kit/foundation/logger/handlers"
 	"github.com/eskandaridanial/go-starter-kit/foundation/logger/hooks"
 )
 
 func main() {
-	log := logger.NewLogger(
-		logger.WithLevel(logger.Debug),
-		logger.WithHandler(&handlers.ConsoleHandler{Formatter: logger.NewJSONFormatter()}),
+	c, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	log, err := logger.NewFromConfig(c,
 		logger.WithField(logger.String("key", "value")),
 		logger.WithHook(&hooks.DefaultHook{}),
 		logger.WithContext(context.Background()),
 		logger.WithBuildInfo("OS_ENV_FOR_VERSION", "OS_ENV_FOR_COMMIT", "OS_ENV_FOR_TIME", true),
 		logger.WithRuntimeInfo(true),
-		logger.WithService("auth"),
-		logger.WithServiceEnv("OS_ENV_FOR_SERVICE_ENV"),
-		logger.WithEnvironment("dev"),
-		logger.WithEnvironmentEnv("OS_ENV_FOR_ENV"),
-		logger.WithTraceIdKey("OS_ENV_FOR_TRACE_ID_KEY"),
 	)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 	defer log.Close()
 
 	log.Debug("user logged in")
@@ -45,3 +51,31 @@ func main() {
 	log.WarnCtx(context.Background(), "user logged in")
 	log.WarnCtx(context.Background(), "user logged in", logger.String("key", "value"))
 }
+
+// function 'loadConfig' loads the logger configuration from the file named by "LOG_CONFIG",
+// or from the "LOG_" environment variables with defaults for the settings that are not set
+func loadConfig() (logger.Config, error) {
+	if path := os.Getenv("LOG_CONFIG"); path != "" {
+		return logger.LoadConfigFile(path)
+	}
+
+	c, err := logger.LoadConfigEnv("LOG")
+	if err != nil {
+		return logger.Config{}, err
+	}
+	for _, d := range []struct {
+		dst *string
+		val string
+	}{
+		{&c.Level, "debug"},
+		{&c.Format, "json"},
+		{&c.TraceIdKey, "${OS_ENV_FOR_TRACE_ID_KEY:-trace_id}"},
+		{&c.Service, "${OS_ENV_FOR_SERVICE_ENV:-auth}"},
+		{&c.Environment, "${OS_ENV_FOR_ENV:-dev}"},
+	} {
+		if *d.dst == "" {
+			*d.dst = d.val
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+// constant 'DefaultRequestIdHeader' is the header carrying the request id in case of missing header
+const DefaultRequestIdHeader = "X-Request-ID"
+
+// variable 'DefaultSkipPaths' are the health check paths that are not access logged by default
+var DefaultSkipPaths = []string{"/health", "/healthz", "/livez", "/readyz"}
+
+// struct 'Middleware' represents an HTTP middleware for request context and access logging,
+// 'TraceIdKey' must match the key given to 'logger.WithTraceIdKey',
+// 'SkipPaths' are paths whose requests are not access logged while they still get a request context
+type Middleware struct {
+	Logger          *logger.Logger
+	TraceIdKey      string
+	RequestIdHeader string
+	SkipPaths       []string
+}
+
+// function 'New' creates a new middleware logging to the given logger,
+// it skips the health check paths defined as 'DefaultSkipPaths'
+func New(l *logger.Logger) *Middleware {
+	return &Middleware{
+		Logger:          l,
+		RequestIdHeader: DefaultRequestIdHeader,
+		SkipPaths:       DefaultSkipPaths,
+	}
+}
+
+// function 'Handler' wraps the given handler, for every request it:
+//   - reads the request id from the request id header or generates one, and echoes it in the response
+//   - reads the trace id from the W3C 'traceparent' header or generates one, and starts a new span
+//   - stores the logger, the trace id and the request_id and span_id fields in the request context,
+//     so the '*Ctx' methods of the logger pick them up
+//   - recovers panics, logs them at error level with their stack and responds with status 500,
+//     when the response was already started its status is kept and logged by the access record
+//   - logs one access record with method, path, status, bytes, duration, remote address and user agent
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+
+		header := m.RequestIdHeader
+		if header == "" {
+			header = DefaultRequestIdHeader
+		}
+		requestId := req.Header.Get(header)
+		if requestId == "" {
+			requestId = randomHex(16)
+		}
+		w.Header().Set(header, requestId)
+
+		traceId, ok := parseTraceparent(req.Header.Get("traceparent"))
+		if !ok {
+			traceId = randomHex(16)
+		}
+
+		ctx := logger.ContextWithLogger(req.Context(), m.Logger)
+		ctx = logger.ContextWithTraceId(ctx, m.TraceIdKey, traceId)
+		ctx = logger.ContextWithFields(ctx,
+			logger.String("request_id", requestId),
+			logger.String("span_id", randomHex(8)),
+		)
+		req = req.WithContext(ctx)
+
+		rw := &responseWriter{ResponseWriter: w}
+		defer func() {
+			if p := recover(); p != nil {
+				if p == http.ErrAbortHandler {
+					// the client went away, net/http handles this panic silently
+					panic(p)
+				}
+				m.Logger.ErrorCtx(ctx, "panic recovered", logger.Err(newPanicError(p)))
+				if !rw.wroteHeader {
+					http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}
+			if !m.skip(req.URL.Path) {
+				m.access(ctx, req, rw, time.Since(start))
+			}
+		}()
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// function 'access' logs the access record of a request,
+// server errors are logged at error level and other responses at info level
+func (m *Middleware) access(ctx context.Context, req *http.Request, rw *responseWriter, duration time.Duration) {
+	status := rw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	fields := []logger.Field{
+		logger.String("method", req.Method),
+		logger.String("path", req.URL.Path),
+		logger.Int("status", status),
+		logger.Int("bytes", rw.bytes),
+		{Key: "duration_ms", Value: float64(duration.Microseconds()) / 1000},
+		logger.String("remote_addr", req.RemoteAddr),
+		logger.String("user_agent", req.UserAgent()),
+	}
+	if status >= http.StatusInternalServerError {
+		m.Logger.ErrorCtx(ctx, "http request", fields...)
+		return
+	}
+	m.Logger.InfoCtx(ctx, "http request", fields...)
+}
+
+// function 'skip' reports whether requests to the given path are not access logged
+func (m *Middleware) skip(path string) bool {
+	for _, p := range m.SkipPaths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// struct 'responseWriter' records the status and the number of bytes written to a response
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+// function 'WriteHeader' records the status and writes the header
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// function 'Write' records the number of bytes written
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// function 'Flush' flushes the response when the underlying writer supports it
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+		f.Flush()
+	}
+}
+
+// function 'Hijack' takes over the connection when the underlying writer supports it, e.g. for websockets,
+// the response is recorded with status 101 unless a status was written before
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("hijack %T: %w", w.ResponseWriter, http.ErrNotSupported)
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && !w.wroteHeader {
+		w.status = http.StatusSwitchingProtocols
+		w.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+// function 'Unwrap' returns the underlying writer, it is used by 'http.ResponseController'
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// struct 'panicError' represents a recovered panic, it implements 'logger.StackTracer'
+type panicError struct {
+	value any
+	stack []logger.StackFrame
+}
+
+// function 'newPanicError' creates a 'panicError' with the stack of the panicking goroutine,
+// it is called from the deferred function so this function, the deferred function and the
+// runtime panic frame are skipped
+func newPanicError(value any) *panicError {
+	return &panicError{value: value, stack: logger.CaptureStack(3, 0)}
+}
+
+// function 'Error' returns the panic value as a message
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+// function 'Unwrap' returns the panic value when it is an error
+func (e *panicError) Unwrap() error {
+	if err, ok := e.value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// function 'StackTrace' returns the stack of the panicking goroutine
+func (e *panicError) StackTrace() []logger.StackFrame {
+	return e.stack
+}
+
+// function 'parseTraceparent' returns the trace id of a W3C 'traceparent' header,
+// e.g. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+func parseTraceparent(header string) (string, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", false
+	}
+	if !isHex(parts[1]) || strings.Trim(parts[1], "0") == "" || !isHex(parts[2]) {
+		return "", false
+	}
+	return strings.ToLower(parts[1]), true
+}
+
+// function 'isHex' reports whether s only contains hexadecimal digits
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// function 'randomHex' returns n random bytes encoded as hexadecimal
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms, a fixed id keeps the request going
+		return strings.Repeat("0", 2*n-1) + "1"
+	}
+	return hex.EncodeToString(b)
+}
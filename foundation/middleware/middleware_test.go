@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger/loggertest"
+)
+
+func TestHandlerKeepsStatusOfStartedResponseOnPanic(t *testing.T) {
+	l, rec := loggertest.NewTestLogger(t)
+	h := New(l).Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("boom")
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	if w.Code != http.StatusAccepted {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusAccepted)
+	}
+	loggertest.HasRecord(t, rec, loggertest.Level(logger.Error), loggertest.Message("panic recovered"))
+	loggertest.HasRecord(t, rec, loggertest.Message("http request"), loggertest.Field("status", http.StatusAccepted))
+}
+
+func TestHandlerRespondsWith500OnPanic(t *testing.T) {
+	l, rec := loggertest.NewTestLogger(t)
+	h := New(l).Handler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(errors.New("boom"))
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	loggertest.HasRecord(t, rec, loggertest.Level(logger.Error), loggertest.Message("http request"),
+		loggertest.Field("status", http.StatusInternalServerError))
+}
+
+func TestHandlerSupportsHijack(t *testing.T) {
+	l, rec := loggertest.NewTestLogger(t)
+	h := New(l).Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\nhello")
+		rw.Flush()
+	}))
+	served := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(served)
+		h.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/ws", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "echo")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("got status %d", resp.StatusCode)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.HasPrefix(string(body), "hello") {
+		t.Errorf("got body %q", body)
+	}
+	<-served
+	loggertest.HasRecord(t, rec, loggertest.Message("http request"), loggertest.Field("status", http.StatusSwitchingProtocols))
+}
+
+func TestHijackWithoutSupport(t *testing.T) {
+	rw := &responseWriter{ResponseWriter: httptest.NewRecorder()}
+	if _, _, err := rw.Hijack(); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("got %v, want %v", err, http.ErrNotSupported)
+	}
+}
@@ -0,0 +1,247 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+)
+
+// constant 'defaultMaxBodyBytes' is the maximum number of logged body bytes in case of missing limit
+const defaultMaxBodyBytes = 4096
+
+// variable 'DefaultRedactedParams' are the query parameters whose values are redacted by default
+var DefaultRedactedParams = []string{
+	"access_token", "api_key", "apikey", "code", "key", "password", "secret", "signature", "token",
+}
+
+// struct 'Transport' implements 'http.RoundTripper' interface,
+// it logs every outbound request with method, redacted URL, status, duration and retries,
+// and injects the trace id of the request context as a W3C 'traceparent' header:
+//   - 'Base' sends the requests, 'http.DefaultTransport' when nil
+//   - 'Logger' receives the records, the logger of the request context when nil
+//   - 'TraceIdKey' must match the key given to 'logger.WithTraceIdKey'
+//   - 'RedactedParams' are the query parameters whose values are replaced, 'DefaultRedactedParams' when nil
+//   - 'LogBodies' adds up to 'MaxBodyBytes' of the request and response bodies when debug is enabled
+//   - 'MaxRetries' retries idempotent requests on network errors, 429 and 5xx responses,
+//     waiting 'RetryBackoff' doubled after every attempt
+type Transport struct {
+	Base           http.RoundTripper
+	Logger         *logger.Logger
+	TraceIdKey     string
+	RedactedParams []string
+	LogBodies      bool
+	MaxBodyBytes   int
+	MaxRetries     int
+	RetryBackoff   time.Duration
+}
+
+// function 'NewTransport' creates a new 'Transport' wrapping the given round tripper and logging to the given logger
+func NewTransport(base http.RoundTripper, l *logger.Logger) *Transport {
+	return &Transport{Base: base, Logger: l}
+}
+
+// function 'RoundTrip' sends the request and logs its outcome
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	l := t.Logger
+	if l == nil {
+		l = logger.FromContext(ctx)
+	}
+	start := time.Now()
+
+	// a round tripper must not modify the request it was given
+	req = req.Clone(ctx)
+	if req.Header.Get("traceparent") == "" {
+		if traceparent := t.traceparent(ctx); traceparent != "" {
+			req.Header.Set("traceparent", traceparent)
+		}
+	}
+
+	logBodies := t.LogBodies && l.Enabled(logger.Debug)
+	var reqBody []byte
+	if logBodies {
+		reqBody = t.peekRequestBody(req)
+	}
+
+	resp, retries, err := t.send(req)
+	fields := []logger.Field{
+		logger.String("method", req.Method),
+		logger.String("url", t.redact(req.URL)),
+		{Key: "duration_ms", Value: float64(time.Since(start).Microseconds()) / 1000},
+		logger.Int("retries", retries),
+	}
+	if logBodies && len(reqBody) > 0 {
+		fields = append(fields, logger.String("request_body", string(reqBody)))
+	}
+
+	if err != nil {
+		l.ErrorCtx(ctx, "http client request failed", append(fields, logger.Err(err))...)
+		return nil, err
+	}
+
+	fields = append(fields, logger.Int("status", resp.StatusCode))
+	if logBodies {
+		if body := t.peekResponseBody(resp); len(body) > 0 {
+			fields = append(fields, logger.String("response_body", string(body)))
+		}
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		l.ErrorCtx(ctx, "http client request", fields...)
+	} else {
+		l.InfoCtx(ctx, "http client request", fields...)
+	}
+	return resp, nil
+}
+
+// function 'send' sends the request retrying it when allowed, it returns the number of retries
+func (t *Transport) send(req *http.Request) (*http.Response, int, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	backoff := t.RetryBackoff
+	if backoff <= 0 {
+		backoff = 200 * time.Millisecond
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := base.RoundTrip(req)
+		if attempt >= t.MaxRetries || !retryable(req, resp, err) {
+			return resp, attempt, err
+		}
+
+		next, rewindErr := rewind(req)
+		if rewindErr != nil {
+			return resp, attempt, err
+		}
+		if resp != nil {
+			// the body is drained so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, attempt, req.Context().Err()
+		case <-time.After(backoff << attempt):
+		}
+		req = next
+	}
+}
+
+// function 'retryable' reports whether a request may be sent again after the given outcome,
+// only idempotent methods are retried
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodTrace:
+	default:
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// function 'rewind' returns a copy of the request with a fresh body for another attempt
+func rewind(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return next, nil
+	}
+	if req.GetBody == nil {
+		return nil, errors.New("request body cannot be replayed")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	next.Body = body
+	return next, nil
+}
+
+// function 'traceparent' returns the 'traceparent' header for the trace id of the context,
+// trace ids that are not 32 hexadecimal digits cannot be propagated as W3C trace context
+func (t *Transport) traceparent(ctx context.Context) string {
+	traceId := strings.ToLower(logger.TraceIdFromContext(ctx, t.TraceIdKey))
+	if len(traceId) != 32 || !isHex(traceId) {
+		return ""
+	}
+	return "00-" + traceId + "-" + randomHex(8) + "-01"
+}
+
+// function 'redact' returns the URL with the values of sensitive query parameters and the password replaced
+func (t *Transport) redact(u *url.URL) string {
+	params := t.RedactedParams
+	if params == nil {
+		params = DefaultRedactedParams
+	}
+
+	redacted := *u
+	if redacted.RawQuery != "" {
+		query := redacted.Query()
+		for name, values := range query {
+			for _, p := range params {
+				if strings.EqualFold(name, p) {
+					for i := range values {
+						values[i] = "REDACTED"
+					}
+				}
+			}
+		}
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.Redacted()
+}
+
+// function 'maxBodyBytes' returns the maximum number of logged body bytes
+func (t *Transport) maxBodyBytes() int {
+	if t.MaxBodyBytes > 0 {
+		return t.MaxBodyBytes
+	}
+	return defaultMaxBodyBytes
+}
+
+// function 'peekRequestBody' returns the start of the request body leaving the body intact
+func (t *Transport) peekRequestBody(req *http.Request) []byte {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil
+		}
+		defer body.Close()
+		head, _ := io.ReadAll(io.LimitReader(body, int64(t.maxBodyBytes())))
+		return head
+	}
+
+	head, _ := io.ReadAll(io.LimitReader(req.Body, int64(t.maxBodyBytes())))
+	req.Body = &peekedBody{Reader: io.MultiReader(bytes.NewReader(head), req.Body), Closer: req.Body}
+	return head
+}
+
+// function 'peekResponseBody' returns the start of the response body leaving the body intact
+func (t *Transport) peekResponseBody(resp *http.Response) []byte {
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return nil
+	}
+	head, _ := io.ReadAll(io.LimitReader(resp.Body, int64(t.maxBodyBytes())))
+	resp.Body = &peekedBody{Reader: io.MultiReader(bytes.NewReader(head), resp.Body), Closer: resp.Body}
+	return head
+}
+
+// struct 'peekedBody' represents a body whose start was read for logging and is read again first
+type peekedBody struct {
+	io.Reader
+	io.Closer
+}
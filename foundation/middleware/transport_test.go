@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger"
+	"github.com/eskandaridanial/go-starter-kit/foundation/logger/loggertest"
+)
+
+func TestTransportRedactsURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("token") != "s3cr3t" {
+			t.Errorf("the request sent was redacted: %s", r.URL)
+		}
+	}))
+	defer server.Close()
+
+	l, rec := loggertest.NewTestLogger(t)
+	client := &http.Client{Transport: NewTransport(nil, l)}
+	u := strings.Replace(server.URL, "http://", "http://bob:hunter2@", 1) + "/search?q=shoes&token=s3cr3t&API_KEY=k"
+	resp, err := client.Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	got := loggertest.HasRecord(t, rec, loggertest.Message("http client request"), loggertest.Field("status", 200))
+	for _, f := range got.Fields {
+		if f.Key != "url" {
+			continue
+		}
+		url := f.Value.(string)
+		for _, secret := range []string{"s3cr3t", "hunter2", "=k"} {
+			if strings.Contains(url, secret) {
+				t.Errorf("url %q contains %q", url, secret)
+			}
+		}
+		if !strings.Contains(url, "q=shoes") || !strings.Contains(url, "token=REDACTED") {
+			t.Errorf("got url %q", url)
+		}
+	}
+}
+
+func TestTransportRetriesIdempotentRequests(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method == http.MethodPut && string(body) != "payload" {
+			t.Errorf("attempt %d got body %q", calls.Load()+1, body)
+		}
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	l, rec := loggertest.NewTestLogger(t)
+	transport := NewTransport(nil, l)
+	transport.MaxRetries = 3
+	transport.RetryBackoff = time.Millisecond
+	client := &http.Client{Transport: transport}
+
+	req, _ := http.NewRequest(http.MethodPut, server.URL, strings.NewReader("payload"))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" || calls.Load() != 3 {
+		t.Errorf("got status %d, body %q after %d calls", resp.StatusCode, body, calls.Load())
+	}
+	loggertest.HasRecord(t, rec, loggertest.Field("retries", 2), loggertest.Field("status", 200))
+
+	calls.Store(0)
+	rec.Reset()
+	resp, err = client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if calls.Load() != 1 {
+		t.Errorf("POST was sent %d times", calls.Load())
+	}
+	loggertest.HasRecord(t, rec, loggertest.Level(logger.Error), loggertest.Field("retries", 0), loggertest.Field("status", 503))
+}
+
+func TestTransportPropagatesTraceparent(t *testing.T) {
+	const traceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+	received := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	l, _ := loggertest.NewTestLogger(t)
+	client := &http.Client{Transport: NewTransport(nil, l)}
+	send := func(ctx context.Context, header string) string {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		if header != "" {
+			req.Header.Set("traceparent", header)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return <-received
+	}
+
+	got := send(logger.ContextWithTraceId(context.Background(), "", traceId), "")
+	if parsed, ok := parseTraceparent(got); !ok || parsed != traceId || !strings.HasSuffix(got, "-01") {
+		t.Errorf("got traceparent %q", got)
+	}
+
+	const existing = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	if got := send(logger.ContextWithTraceId(context.Background(), "", traceId), existing); got != existing {
+		t.Errorf("existing traceparent was replaced by %q", got)
+	}
+	if got := send(logger.ContextWithTraceId(context.Background(), "", "not-a-w3c-id"), ""); got != "" {
+		t.Errorf("got traceparent %q for a trace id that is not W3C", got)
+	}
+}
+
+func TestTransportLogsBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	}))
+	defer server.Close()
+
+	l, rec := loggertest.NewTestLogger(t)
+	transport := NewTransport(nil, l)
+	transport.LogBodies = true
+	transport.MaxBodyBytes = 4
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("hello world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "hello world" {
+		t.Errorf("logging consumed the body, got %q", body)
+	}
+	loggertest.HasRecord(t, rec, loggertest.Field("request_body", "hell"), loggertest.Field("response_body", "hell"))
+}